@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ahrtr/chess/rules"
+)
+
+// The positions are the ones of the perft tests, which cover the opening,
+// the middlegame and the endgame.
+var benchPositions = []string{
+	rules.StartFEN,
+	"r1ba1a3/4kn3/2n1b4/pNp1p1p1p/4c4/6P2/P1P2R2P/1CcC5/9/2BAKAB2 w - - 0 1",
+	"1cbak4/9/n2a5/2p1p3p/5cp2/2n2N3/6PCP/3AB4/2C6/3A1K1N1 w - - 0 1",
+	"5a3/3k5/3aR4/9/5r3/5n3/9/3A1A3/5K3/2BC2B2 w - - 0 1",
+	"CRN1k1b2/3ca4/4ba3/9/2nr5/9/9/4B4/4A4/4KA3 w - - 0 1",
+	"R1N1k1b2/9/3aba3/9/2nr5/2B6/9/4B4/4A4/4KA3 w - - 0 1",
+}
+
+// bench searches the positions to a fixed depth, and prints the node counts
+// and the cutoff statistics, so the effect of a search feature can be
+// measured by turning it off and on, e.g.
+//
+//	go run ./cmd/bench -depth 5 -ordering=false
+//	go run ./cmd/bench -depth 5 -ordering=true
+//
+// or the evaluators can be compared by the best moves and the node counts:
+//
+//	go run ./cmd/bench -depth 5 -eval material
+//	go run ./cmd/bench -depth 5 -eval positional
+func main() {
+	fen := flag.String("fen", "", "search only the position in FEN (defaults to the built-in positions).")
+	depth := flag.Int("depth", 5, "the depth to search (defaults to 5).")
+	hash := flag.Int("hash", rules.DefaultEngineOptions.HashSize, "the size of the transposition table in MB (defaults to 16).")
+	ordering := flag.Bool("ordering", rules.DefaultEngineOptions.MoveOrdering, "order the moves (defaults to true).")
+	delta := flag.Bool("delta", rules.DefaultEngineOptions.DeltaPruning, "delta pruning in the quiescence search (defaults to true).")
+	null := flag.Bool("null", rules.DefaultEngineOptions.NullMove, "null move pruning (defaults to true).")
+	lmr := flag.Bool("lmr", rules.DefaultEngineOptions.LateMoveReductions, "late move reductions (defaults to true).")
+	checkExt := flag.Bool("check-ext", rules.DefaultEngineOptions.CheckExtensions, "check extensions (defaults to true).")
+	threads := flag.Int("threads", rules.DefaultEngineOptions.Threads, "the number of the search threads (defaults to 1).")
+	eval := flag.String("eval", "positional", "the evaluator: positional or material (defaults to positional).")
+	flag.Parse()
+
+	if *depth < 1 {
+		log.Fatalf("Invalid depth: %d", *depth)
+	}
+	evaluator, ok := rules.Evaluators[*eval]
+	if !ok {
+		log.Fatalf("Invalid evaluator: %s", *eval)
+	}
+	positions := benchPositions
+	if *fen != "" {
+		positions = []string{*fen}
+	}
+	options := rules.EngineOptions{
+		HashSize:           *hash,
+		DeltaPruning:       *delta,
+		MoveOrdering:       *ordering,
+		NullMove:           *null,
+		LateMoveReductions: *lmr,
+		CheckExtensions:    *checkExt,
+		Threads:            *threads,
+		Evaluator:          evaluator,
+	}
+
+	var totalNodes, totalCutoffs, totalFirst int
+	var totalTime time.Duration
+	for i, f := range positions {
+		b, err := rules.ParseFEN(f)
+		if err != nil {
+			log.Fatalf("Failed to parse the FEN: %v", err)
+		}
+
+		// A fresh engine for each position, so the positions don't affect
+		// each other through the transposition table.
+		start := time.Now()
+		result := rules.NewEngine(options).Search(context.Background(), b, rules.SearchLimits{Depth: *depth})
+		elapsed := time.Since(start)
+
+		fmt.Printf("%d. %s  score: %d  nodes: %d  cutoffs: %d (first move: %s)  time: %s\n   pv: %s\n",
+			i+1, result.Move.ICCS(), result.Score, result.Nodes, result.Cutoffs,
+			percent(result.FirstMoveCutoffs, result.Cutoffs), elapsed.Round(time.Millisecond), pv(result.PV))
+		totalNodes += result.Nodes
+		totalCutoffs += result.Cutoffs
+		totalFirst += result.FirstMoveCutoffs
+		totalTime += elapsed
+	}
+	fmt.Printf("\nNodes:   %d\nCutoffs: %d (first move: %s)\nTime:    %s\n",
+		totalNodes, totalCutoffs, percent(totalFirst, totalCutoffs), totalTime.Round(time.Millisecond))
+}
+
+// pv formats the principal variation in ICCS.
+func pv(moves []rules.Move) string {
+	s := make([]string, len(moves))
+	for i, m := range moves {
+		s[i] = m.ICCS()
+	}
+	return strings.Join(s, " ")
+}
+
+func percent(n, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/ahrtr/chess/rules"
+)
+
+// perft prints the node count under each root move (divide) and the
+// total node count of the position, e.g.
+//
+//	go run ./cmd/perft -depth 4 -fen "rnbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C5C1/9/RNBAKABNR w - - 0 1"
+func main() {
+	fen := flag.String("fen", rules.StartFEN, "the position in FEN (defaults to the initial position).")
+	depth := flag.Int("depth", 3, "the depth of the move tree (defaults to 3).")
+	flag.Parse()
+
+	if *depth < 1 {
+		log.Fatalf("Invalid depth: %d", *depth)
+	}
+	b, err := rules.ParseFEN(*fen)
+	if err != nil {
+		log.Fatalf("Failed to parse the FEN: %v", err)
+	}
+
+	start := time.Now()
+	entries := b.Divide(*depth)
+	elapsed := time.Since(start)
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Move.ICCS() < entries[j].Move.ICCS()
+	})
+	total := 0
+	for _, e := range entries {
+		fmt.Printf("%s: %d\n", e.Move.ICCS(), e.Nodes)
+		total += e.Nodes
+	}
+	fmt.Printf("\nMoves: %d\nNodes: %d\nTime:  %s\n", len(entries), total, elapsed.Round(time.Millisecond))
+}
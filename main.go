@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"image"
 	"log"
+	"runtime"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 
@@ -14,29 +17,40 @@ import (
 
 const (
 	buttonX0    = 128
-	buttonWidth = 96
+	buttonWidth = 72
 	buttonGap   = 12
 	buttonY0    = 12
 	buttonY1    = 48
 )
 
 type Game struct {
-	chessBoard *rules.Board
+	chessBoard *ui.Board
 	undoButton *ui.Button
 	redoButton *ui.Button
 
-	hintButton   *ui.Button
-	isAIThinking bool
+	hintButton *ui.Button
+	// the engine of the AI, which is shared by the searches of the game
+	engine *rules.Engine
+	// the running search of the AI, nil if the AI isn't thinking. It's only
+	// accessed by the game loop, and the result is polled in Update.
+	aiJob *rules.SearchJob
+	// how long the AI thinks for a hint
+	thinkTime time.Duration
 
-	history        []*rules.Board
+	drawButton   *ui.Button
+	resignButton *ui.Button
+	newButton    *ui.Button
+
+	history        []*ui.Board
 	historyPointer int
 }
 
-func NewGame(selfColor rules.PieceColor) *Game {
-	board, err := rules.NewBoard(selfColor)
+func NewGame(selfColor rules.PieceColor, position *rules.Board, notation ui.Notation, engine *rules.Engine, thinkTime time.Duration) *Game {
+	board, err := ui.NewBoard(selfColor, position)
 	if err != nil {
 		log.Fatalf("Failed to create the board: %v", err)
 	}
+	board.SetNotation(notation)
 
 	g := &Game{
 		chessBoard: board,
@@ -44,6 +58,12 @@ func NewGame(selfColor rules.PieceColor) *Game {
 		redoButton: ui.NewButton(image.Rect(buttonX0+(buttonWidth+buttonGap)*1, buttonY0, buttonX0+(buttonWidth+buttonGap)*1+buttonWidth, buttonY1), "Redo", nil),
 
 		hintButton: ui.NewButton(image.Rect(buttonX0+(buttonWidth+buttonGap)*2, buttonY0, buttonX0+(buttonWidth+buttonGap)*2+buttonWidth, buttonY1), "Hint", nil),
+		engine:     engine,
+		thinkTime:  thinkTime,
+
+		drawButton:   ui.NewButton(image.Rect(buttonX0+(buttonWidth+buttonGap)*3, buttonY0, buttonX0+(buttonWidth+buttonGap)*3+buttonWidth, buttonY1), "Draw", nil),
+		resignButton: ui.NewButton(image.Rect(buttonX0+(buttonWidth+buttonGap)*4, buttonY0, buttonX0+(buttonWidth+buttonGap)*4+buttonWidth, buttonY1), "Resign", nil),
+		newButton:    ui.NewButton(image.Rect(buttonX0+(buttonWidth+buttonGap)*5, buttonY0, buttonX0+(buttonWidth+buttonGap)*5+buttonWidth, buttonY1), "New", nil),
 
 		history:        nil,
 		historyPointer: -1,
@@ -60,6 +80,29 @@ func NewGame(selfColor rules.PieceColor) *Game {
 		g.aiRun()
 	})
 
+	// Both players share the same screen, so clicking the button means
+	// they agree to draw.
+	g.drawButton.SetOnClick(func(_ *ui.Button) {
+		if g.chessBoard.Position().IsGameOver() {
+			return
+		}
+		g.stopAI()
+		g.chessBoard.AgreeDraw()
+		g.backup()
+	})
+	// The side to move resigns.
+	g.resignButton.SetOnClick(func(_ *ui.Button) {
+		if g.chessBoard.Position().IsGameOver() {
+			return
+		}
+		g.stopAI()
+		g.chessBoard.Resign()
+		g.backup()
+	})
+	g.newButton.SetOnClick(func(_ *ui.Button) {
+		g.newGame()
+	})
+
 	return g
 }
 
@@ -74,6 +117,7 @@ func (g *Game) backup() {
 
 func (g *Game) undo() {
 	if g.historyPointer > 0 {
+		g.stopAI()
 		g.historyPointer--
 		g.historyOperation()
 	}
@@ -81,6 +125,7 @@ func (g *Game) undo() {
 
 func (g *Game) redo() {
 	if g.historyPointer < len(g.history)-1 {
+		g.stopAI()
 		g.historyPointer++
 		g.historyOperation()
 	}
@@ -92,42 +137,69 @@ func (g *Game) historyOperation() {
 	g.chessBoard = clone
 }
 
+// newGame starts over from the initial position of the game.
+func (g *Game) newGame() {
+	g.stopAI()
+	board := g.history[0].Clone()
+	board.ResetTimer()
+	g.chessBoard = board
+	g.history = g.history[:0]
+	g.historyPointer = -1
+	g.backup()
+}
+
 func (g *Game) aiRun() {
-	if g.isAIThinking {
+	if g.aiJob != nil || g.chessBoard.Position().IsGameOver() {
 		return
 	}
 
-	g.isAIThinking = true
 	g.chessBoard.StartAI()
+	// The search runs on a snapshot of the position in the background.
+	// Performance history:
+	//   1. 2024-12-31 depth = 4, took 1m30s
+	//      Very basic minimax algorithm with alpha-beta pruning improvement.
+	//   2. 2026-10-17 depth = 4, took 58ms; depth = 8, took 2.1s (1 thread)
+	//      Measured by cmd/bench on the start position. See the git history
+	//      for the improvements in between.
+	g.aiJob = g.engine.StartSearch(context.Background(), g.chessBoard.Position(), rules.SearchLimits{Time: g.thinkTime})
+}
 
-	// let's do it async
-	go func() {
-		defer func() {
-			g.isAIThinking = false
-		}()
-
-		cloneBoard := g.chessBoard.Clone()
-		// Performance history:
-		//   1. 2024-12-31 depth = 4, took 1m30s
-		//      Very basic minimax algorithm with alpha-beta pruning improvement.
-		bestMove := cloneBoard.GetBestMove(4)
+// stopAI cancels the running search of the AI, if any. The search is
+// canceled once the position changes, and its result is discarded, so
+// a stale hint is never applied to a different position.
+func (g *Game) stopAI() {
+	if g.aiJob != nil {
+		g.aiJob.Cancel()
+		g.aiJob = nil
+	}
+}
 
-		g.chessBoard.StopAI(fmt.Sprintf("Best move: %s", bestMove.String()))
-	}()
+// pollAI shows the hint once the search of the AI is done.
+func (g *Game) pollAI() {
+	if g.aiJob == nil {
+		return
+	}
+	select {
+	case result := <-g.aiJob.Result():
+		g.chessBoard.StopAI(result)
+		g.aiJob = nil
+	default:
+	}
 }
 
 func (g *Game) Update() error {
-	// do nothing when the AI is thinking
-	if g.isAIThinking {
-		return nil
-	}
+	g.pollAI()
 
 	if g.chessBoard.Update() {
+		g.stopAI()
 		g.backup()
 	}
 	g.undoButton.Update()
 	g.redoButton.Update()
 	g.hintButton.Update()
+	g.drawButton.Update()
+	g.resignButton.Update()
+	g.newButton.Update()
 	return nil
 }
 
@@ -136,31 +208,94 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	g.undoButton.Draw(screen)
 	g.redoButton.Draw(screen)
 	g.hintButton.Draw(screen)
+	g.drawButton.Draw(screen)
+	g.resignButton.Draw(screen)
+	g.newButton.Draw(screen)
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 	return outsideWidth, outsideHeight
 }
 
+var (
+	colorFlag    = flag.String("color", "red", "decide self color(defaults to red).")
+	fenFlag      = flag.String("fen", "", "start from the position in FEN (defaults to the initial position).")
+	notationFlag = flag.String("notation", "wxf", "the notation of the moves: wxf, iccs or chinese (defaults to wxf).")
+	rulesFlag    = flag.String("rules", "axf", "the rules to judge the repeated positions: axf or chinese (defaults to axf).")
+
+	moveLimitFlag    = flag.Int("move-limit", rules.DefaultDrawRules.MoveLimit, "draw after the number of moves without any capture, 0 to disable (defaults to 60).")
+	materialDrawFlag = flag.Bool("material-draw", rules.DefaultDrawRules.InsufficientMaterial, "draw when neither side has any attacking piece (defaults to true).")
+
+	thinkTimeFlag = flag.Duration("think-time", 5*time.Second, "how long the AI thinks for a hint (defaults to 5s).")
+	hashFlag      = flag.Int("hash", rules.DefaultEngineOptions.HashSize, "the size of the transposition table of the AI in MB (defaults to 16).")
+	threadsFlag   = flag.Int("threads", runtime.NumCPU(), "the number of the threads of the AI (defaults to the number of the CPUs).")
+	evalFlag      = flag.String("eval", "positional", "the evaluator of the AI: positional or material (defaults to positional).")
+)
+
 func selfColor() rules.PieceColor {
-	color := flag.String("color", "red", "decide self color(defaults to red).")
-	flag.Parse()
-	if *color == string(rules.Red) {
+	if *colorFlag == string(rules.Red) {
 		return rules.Red
 	}
-	if *color == string(rules.Black) {
+	if *colorFlag == string(rules.Black) {
 		return rules.Black
 	}
-	panic(fmt.Sprintf("invalid color: %s", *color))
+	panic(fmt.Sprintf("invalid color: %s", *colorFlag))
+}
+
+func notation() ui.Notation {
+	switch n := ui.Notation(*notationFlag); n {
+	case ui.NotationWXF, ui.NotationICCS, ui.NotationChinese:
+		return n
+	}
+	panic(fmt.Sprintf("invalid notation: %s", *notationFlag))
+}
+
+func ruleSet() rules.RuleSet {
+	switch r := rules.RuleSet(*rulesFlag); r {
+	case rules.RuleSetAXF, rules.RuleSetChinese:
+		return r
+	}
+	panic(fmt.Sprintf("invalid rules: %s", *rulesFlag))
+}
+
+func evaluator() rules.Evaluator {
+	if e, ok := rules.Evaluators[*evalFlag]; ok {
+		return e
+	}
+	panic(fmt.Sprintf("invalid evaluator: %s", *evalFlag))
+}
+
+func startPosition() *rules.Board {
+	position := rules.NewBoard()
+	if *fenFlag != "" {
+		var err error
+		if position, err = rules.ParseFEN(*fenFlag); err != nil {
+			log.Fatalf("Failed to parse the FEN: %v", err)
+		}
+	}
+	position.SetRuleSet(ruleSet())
+	position.SetDrawRules(rules.DrawRules{
+		MoveLimit:            *moveLimitFlag,
+		InsufficientMaterial: *materialDrawFlag,
+	})
+	return position
 }
 
 func main() {
-	color := selfColor()
-	game := NewGame(color)
+	flag.Parse()
+	options := rules.DefaultEngineOptions
+	options.HashSize = *hashFlag
+	options.Evaluator = evaluator()
+	options.Threads = *threadsFlag
+	engine := rules.NewEngine(options)
+	game := NewGame(selfColor(), startPosition(), notation(), engine, *thinkTimeFlag)
 
-	ebiten.SetWindowSize(rules.WindowsWidth, rules.WindowsHeight)
+	ebiten.SetWindowSize(ui.WindowsWidth, ui.WindowsHeight)
 	ebiten.SetWindowTitle("中国象棋")
-	if err := ebiten.RunGame(game); err != nil {
+	err := ebiten.RunGame(game)
+	// The window is closed.
+	game.stopAI()
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -3,403 +3,205 @@ package rules
 import (
 	"fmt"
 	"image"
-	"time"
-
-	"github.com/hajimehoshi/ebiten/v2"
-
-	"github.com/ahrtr/chess/utils"
+	"slices"
 )
 
+// Board is the position of a game: the pieces on the board and the side
+// to move. It only contains the game logic, and doesn't depend on any
+// graphics stack, so it can be used by a server or a test binary directly.
+//
+// The board always uses the same encoding regardless of which side the
+// human plays: Black pieces start on the rows 0~4 (top), and Red pieces
+// start on the rows 5~9 (bottom). Flipping the board is up to the renderer.
+//
+// Board is an adapter of the engine's position, which converts between
+// the points (row, column) and the squares of the engine.
 type Board struct {
-	// What's your color: red or black?
-	selfColor PieceColor
-	// Is it the Red's turn to move? Always defaults to true in the beginning.
-	isRedTurn bool
-	// Is the MouseButtonLeft pressed?
-	mouseDown bool
-	// The point clicked by mouse
-	// Note the point.X is the row number (0-9), and point.Y is the column number (0-8).
-	targetPt *image.Point
-	// The piece on the selected point should be displayed in dash circle.
-	// Note the point.X is the row number (0-9), and point.Y is the column number (0-8).
-	selectedFromPoint *image.Point
-	// What's the start time that the player is allowed to move?
-	startTime time.Time
-	// the time that generates the winner. Once the field is set,
-	// then the game is over.
-	finalTime time.Time
-	// the hint from the AI
-	hintFromAI string
-	// whether the AI is working
-	isAIWorking bool
-	// the time when the AI starts to work
-	aiStartTime time.Time
-	aiStopTime  time.Time
-	// The board has 10 rows, and 9 columns
-	pieceMatrix [10][9]*Piece
+	position
+	// The result of the game, and the reason once the game is over.
+	result GameResult
+	reason Reason
+	// The rules to judge the repeated positions.
+	ruleSet RuleSet
+	// The rules to draw the game.
+	drawRules DrawRules
+	// The positions of the game so far, starting from the initial one.
+	history []historyEntry
 }
 
-func NewBoard(selfRole PieceColor) (*Board, error) {
-	if err := initializePieceImageMap(); err != nil {
-		return nil, err
+func NewBoard() *Board {
+	b, err := ParseFEN(StartFEN)
+	if err != nil {
+		panic(fmt.Sprintf("invalid start position: %v", err))
 	}
-
-	return newBoard(selfRole), nil
+	return b
 }
 
 func (b *Board) Clone() *Board {
-	clone := &Board{
-		selfColor:   b.selfColor,
-		isRedTurn:   b.isRedTurn,
-		mouseDown:   b.mouseDown,
-		startTime:   b.startTime,
-		pieceMatrix: b.pieceMatrix,
-	}
-	if b.selectedFromPoint != nil {
-		clone.selectedFromPoint = &image.Point{X: b.selectedFromPoint.X, Y: b.selectedFromPoint.Y}
-	}
-	if b.targetPt != nil {
-		clone.targetPt = &image.Point{X: b.targetPt.X, Y: b.targetPt.Y}
-	}
-	return clone
+	clone := *b
+	clone.undoStack = slices.Clone(b.undoStack)
+	clone.history = slices.Clone(b.history)
+	return &clone
 }
 
-func (b *Board) ResetTimer() {
-	b.startTime = time.Now()
+// IsRedTurn returns true if it's the Red's turn to move.
+func (b *Board) IsRedTurn() bool {
+	return b.isRedTurn
 }
 
-func newBoard(selfRole PieceColor) *Board {
-	// Self is red.
-	// Black pieces are on top and red pieces are at the bottom area.
-	if selfRole == Red {
-		return &Board{
-			selfColor:         Red,
-			isRedTurn:         true,
-			selectedFromPoint: nil,
-			startTime:         time.Now(),
-			pieceMatrix: [10][9]*Piece{
-				// Black pieces (rows 0~4, top-->down)
-				{
-					&Piece{Black, RoleRook},
-					&Piece{Black, RoleHorse},
-					&Piece{Black, RoleBishop},
-					&Piece{Black, RoleGuard},
-					&Piece{Black, RoleKing},
-					&Piece{Black, RoleGuard},
-					&Piece{Black, RoleBishop},
-					&Piece{Black, RoleHorse},
-					&Piece{Black, RoleRook},
-				},
-				{
-					nil, nil, nil, nil, nil, nil, nil, nil, nil,
-				},
-				{
-					nil, &Piece{Black, RoleCannon}, nil, nil, nil, nil, nil, &Piece{Black, RoleCannon}, nil,
-				},
-				{
-					&Piece{Black, RoleSolder},
-					nil,
-					&Piece{Black, RoleSolder},
-					nil,
-					&Piece{Black, RoleSolder},
-					nil,
-					&Piece{Black, RoleSolder},
-					nil,
-					&Piece{Black, RoleSolder},
-				},
-				{
-					nil, nil, nil, nil, nil, nil, nil, nil, nil,
-				},
+// FullMoveNumber returns the number of the full moves, which starts at 1,
+// and is incremented after each Black's move.
+func (b *Board) FullMoveNumber() int {
+	return b.fullMoveNumber
+}
 
-				// Red pieces (rows 5~9, top-->down)
-				{
-					nil, nil, nil, nil, nil, nil, nil, nil, nil,
-				},
-				{
-					&Piece{Red, RoleSolder},
-					nil,
-					&Piece{Red, RoleSolder},
-					nil,
-					&Piece{Red, RoleSolder},
-					nil,
-					&Piece{Red, RoleSolder},
-					nil,
-					&Piece{Red, RoleSolder},
-				},
-				{
-					nil, &Piece{Red, RoleCannon}, nil, nil, nil, nil, nil, &Piece{Red, RoleCannon}, nil,
-				},
-				{
-					nil, nil, nil, nil, nil, nil, nil, nil, nil,
-				},
-				{
-					&Piece{Red, RoleRook},
-					&Piece{Red, RoleHorse},
-					&Piece{Red, RoleBishop},
-					&Piece{Red, RoleGuard},
-					&Piece{Red, RoleKing},
-					&Piece{Red, RoleGuard},
-					&Piece{Red, RoleBishop},
-					&Piece{Red, RoleHorse},
-					&Piece{Red, RoleRook},
-				},
-			},
-		}
-	}
+// SetRuleSet sets the rules to judge the repeated positions, which
+// defaults to RuleSetAXF.
+func (b *Board) SetRuleSet(r RuleSet) {
+	b.ruleSet = r
+}
 
-	// Self is black.
-	// Red pieces are on top and black pieces are at the bottom area.
-	return &Board{
-		selfColor:         Black,
-		isRedTurn:         true,
-		selectedFromPoint: nil,
-		startTime:         time.Now(),
-		pieceMatrix: [10][9]*Piece{
-			// Red pieces (rows 0~4, top-->down)
-			{
-				{Red, RoleRook},
-				{Red, RoleHorse},
-				{Red, RoleBishop},
-				{Red, RoleGuard},
-				{Red, RoleKing},
-				{Red, RoleGuard},
-				{Red, RoleBishop},
-				{Red, RoleHorse},
-				{Red, RoleRook},
-			},
-			{
-				nil, nil, nil, nil, nil, nil, nil, nil, nil,
-			},
-			{
-				nil, {Red, RoleCannon}, nil, nil, nil, nil, nil, {Red, RoleCannon}, nil,
-			},
-			{
-				{Red, RoleSolder},
-				nil,
-				{Red, RoleSolder},
-				nil,
-				{Red, RoleSolder},
-				nil,
-				{Red, RoleSolder},
-				nil,
-				{Red, RoleSolder},
-			},
-			{
-				nil, nil, nil, nil, nil, nil, nil, nil, nil,
-			},
+// IsGameOver returns true if the game has ended in a win or a draw.
+func (b *Board) IsGameOver() bool {
+	return b.isGameOver()
+}
 
-			// Black pieces (rows 5~9, top-->down)
-			{
-				nil, nil, nil, nil, nil, nil, nil, nil, nil,
-			},
-			{
-				{Black, RoleSolder},
-				nil,
-				{Black, RoleSolder},
-				nil,
-				{Black, RoleSolder},
-				nil,
-				{Black, RoleSolder},
-				nil,
-				{Black, RoleSolder},
-			},
-			{
-				nil, {Black, RoleCannon}, nil, nil, nil, nil, nil, {Black, RoleCannon}, nil,
-			},
-			{
-				nil, nil, nil, nil, nil, nil, nil, nil, nil,
-			},
-			{
-				{Black, RoleRook},
-				{Black, RoleHorse},
-				{Black, RoleBishop},
-				{Black, RoleGuard},
-				{Black, RoleKing},
-				{Black, RoleGuard},
-				{Black, RoleBishop},
-				{Black, RoleHorse},
-				{Black, RoleRook},
-			},
-		},
+// Winner returns the winner once the game is over. The second return
+// value is false if the game isn't over yet or it's a draw.
+func (b *Board) Winner() (PieceColor, bool) {
+	return b.result.Winner()
+}
+
+// PieceAt returns the piece on the specified point, or nil if the point is empty
+// or off the board.
+// Note the point.X is the row number (0-9), and point.Y is the column number (0-8).
+func (b *Board) PieceAt(pt image.Point) *Piece {
+	if !isValidPoint(pt) {
+		return nil
+	}
+	p, ok := b.pieceAt(pt.X, pt.Y)
+	if !ok {
+		return nil
 	}
+	return &p
 }
 
-// Update returns true if any piece moves, returns false otherwise.
-func (b *Board) Update() bool {
+// LegalMoves returns all the legal moves of the side to move.
+// It returns nil if the game is over.
+func (b *Board) LegalMoves() []Move {
 	if b.isGameOver() {
-		return false
+		return nil
 	}
+	return b.validMoves()
+}
 
-	moved := false
-
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-		pt := image.Pt(ebiten.CursorPosition())
+// FindMove returns the legal move of the side to move from `from` to `to`.
+// The second return value is false if there is no such legal move.
+func (b *Board) FindMove(from, to image.Point) (Move, bool) {
+	if b.isGameOver() || !isValidPoint(from) || !isValidPoint(to) {
+		return Move{}, false
+	}
+	fromSq := toSquare(from.X, from.Y)
+	slot := int(b.squares[fromSq])
+	if slot == 0 || slotColor(slot) != b.color() {
+		return Move{}, false
+	}
 
-		targetPt := b.findMouseClickedPoint(pt)
-		if targetPt != nil {
-			b.mouseDown = true
-			b.targetPt = targetPt
-		} else {
-			b.mouseDown = false
-			b.targetPt = nil
+	target := newMove(fromSq, toSquare(to.X, to.Y))
+	for _, mv := range b.generatePieceMoves(slot, fromSq, nil) {
+		if mv != target {
+			continue
 		}
-	} else {
-		if b.mouseDown {
-			p := b.pieceMatrix[b.targetPt.X][b.targetPt.Y]
-			if p == nil {
-				if b.selectedFromPoint != nil {
-					selectedPiece := b.pieceMatrix[b.selectedFromPoint.X][b.selectedFromPoint.Y]
-					// check whether it's a valid move.
-					if selectedPiece.validatePieceMove(b.selectedFromPoint.X, b.selectedFromPoint.Y, b.targetPt.X, b.targetPt.Y, b) {
-						b.move(b.selectedFromPoint.X, b.selectedFromPoint.Y, b.targetPt.X, b.targetPt.Y, true)
-						moved = true
-					}
-					b.selectedFromPoint = nil
-				}
-			} else {
-				if b.selectedFromPoint == nil {
-					// You can't select a piece of the wrong color unless you are capturing the opponent's pieces.
-					if (p.color == Red) == b.isRedTurn {
-						b.selectedFromPoint = b.targetPt
-					}
-				} else {
-					// You can't capture a piece of the same color.
-					if (p.color == Red) != b.isRedTurn {
-						// check whether it's a valid capture.
-						selectedPiece := b.pieceMatrix[b.selectedFromPoint.X][b.selectedFromPoint.Y]
-						if selectedPiece.validatePieceMove(b.selectedFromPoint.X, b.selectedFromPoint.Y, b.targetPt.X, b.targetPt.Y, b) {
-							b.move(b.selectedFromPoint.X, b.selectedFromPoint.Y, b.targetPt.X, b.targetPt.Y, true)
-							moved = true
-						}
-					}
-					b.selectedFromPoint = nil
-				}
-			}
+		if !b.isLegal(mv) {
+			return Move{}, false
 		}
-		b.mouseDown = false
+		return b.toMove(mv), true
 	}
-
-	return moved
+	return Move{}, false
 }
 
-// findMouseClickedPoint locates the point clicked by the mouse.
-// Note the input parameter is the position of the cursor when
-// mouse being clicked; while the return parameter is the position
-// [row number(0-9): column number(0-8)] on the board.
-func (b *Board) findMouseClickedPoint(pt image.Point) *image.Point {
-	var (
-		// step of rows and columns
-		widthStep, heightStep = (WindowsWidth - leftMargin*2) / 8, (WindowsHeight - topMargin*2) / 9
-	)
-
-	for i := 0; i < 10; i++ { // 10 rows
-		for j := 0; j < 9; j++ { // 9 columns
-			targetPt := image.Pt(leftMargin+widthStep*j, topMargin+heightStep*i)
-			rect := image.Rect(targetPt.X-imageWidth/2, targetPt.Y-imageHeight/2, targetPt.X+imageWidth/2, targetPt.Y+imageHeight/2)
-			if !utils.IsPointInsideRect(pt, rect) {
-				continue
-			}
-			return &image.Point{X: i, Y: j}
-		}
+// ApplyMove applies the move to the board, and switches the active side.
+// It returns an error if the move isn't legal in the current position.
+func (b *Board) ApplyMove(m Move) error {
+	if !isValidPoint(m.From) || !isValidPoint(m.To) {
+		return fmt.Errorf("invalid move %s: out of the board", m)
 	}
-
+	legal, ok := b.FindMove(m.From, m.To)
+	if !ok || legal.Piece != m.Piece {
+		return fmt.Errorf("illegal move %s", m)
+	}
+	b.move(newMove(toSquare(m.From.X, m.From.Y), toSquare(m.To.X, m.To.Y)))
 	return nil
 }
 
-// findKing returns the position on the board of the king of the specified color.
-func (b *Board) findKing(color PieceColor) image.Point {
-	for i := 0; i <= 9; i++ {
-		for j := 0; j <= 8; j++ {
-			p := b.pieceMatrix[i][j]
-			if p != nil && p.color == color && p.role == RoleKing {
-				return image.Point{X: i, Y: j}
-			}
-		}
-	}
-	panic(fmt.Sprintf("can't find the king: %s", color))
+// move makes the move, records it in the history, and checks whether
+// the game is over.
+func (b *Board) move(mv move) {
+	mover := b.color()
+	capture := b.squares[mv.to()] != 0
+	chased := b.chasedPieces(mover)
+	b.makeMove(mv)
+	b.recordHistory(mover, capture, chased)
+	b.updateResult()
 }
 
-func (b *Board) move(fromX, fromY, toX, toY int, checkWinner bool) {
-	b.pieceMatrix[toX][toY] = b.pieceMatrix[fromX][fromY]
-	b.pieceMatrix[fromX][fromY] = nil
-	b.resetAI()
-
-	if checkWinner {
-		cloneBoard := b.Clone()
-		cloneBoard.switchPlayer()
-		if cloneBoard.isWinner() {
-			b.finalTime = time.Now()
-			return
+// updateResult checks whether the game is over in the current position,
+// which is reached by the last move, or set up by a FEN.
+func (b *Board) updateResult() {
+	if b.isWinner() {
+		reason := ReasonStalemate
+		if b.isInCheck(b.color()) {
+			reason = ReasonCheckmate
 		}
+		b.end(winResult(opponent(b.color())), reason)
+		return
+	}
+	if result := b.judgeRepetition(); result != Ongoing {
+		b.end(result, ReasonRepetition)
+		return
+	}
+	if reason := b.drawReason(); reason != "" {
+		b.end(Draw, reason)
 	}
-	b.switchPlayer()
-}
-
-func (b *Board) switchPlayer() {
-	b.isRedTurn = !b.isRedTurn
-	b.startTime = time.Now()
 }
 
+// validMoves returns all the legal moves of the side to move.
 func (b *Board) validMoves() []Move {
-	color := b.color()
-
-	var allRoutes []Move
-	// get all the valid moves of the pieces of the current active player
-	for i := 0; i <= 9; i++ {
-		for j := 0; j <= 8; j++ {
-			p := b.pieceMatrix[i][j]
-			if p == nil || p.color != color {
-				continue
-			}
-			routes := p.validMoves(b, image.Point{X: i, Y: j})
-			for _, r := range routes {
-				allRoutes = append(allRoutes, Move{Piece: *p, route: r})
-			}
-		}
+	var allMoves []Move
+	for _, mv := range b.legalMoves(nil) {
+		allMoves = append(allMoves, b.toMove(mv))
 	}
-
-	return allRoutes
+	return allMoves
 }
 
 // isWinner should be called right after `move`, to check whether
-// the `move` has resulted to a winner.
+// the `move` has resulted to a winner. The side to move loses if it
+// has no valid moves, no matter whether its king is in danger (将死)
+// or not (困毙), because all the valid moves already make sure that
+// the king isn't in danger after the move.
 func (b *Board) isWinner() bool {
-	allRoutes := b.validMoves()
-	// No valid routes (困毙)
-	if len(allRoutes) == 0 {
-		return true
-	}
-
-	color := b.color()
-	if !isKingInDanger(b, color) {
-		return false
-	}
-
-	// try all the possible valid move, and check whether it can
-	// resolve the danger of the king. If the king won't be in
-	// danger anymore after the possible valid move, then no winner
-	// generated yet.
-	for _, r := range allRoutes {
-		clonedBoard := b.Clone()
-		// The last parameter must be `false`, otherwise it will lead to
-		// indefinite recursion.
-		clonedBoard.move(r.from.X, r.from.Y, r.to.X, r.to.Y, false)
-		if !isKingInDanger(clonedBoard, color) {
-			return false
-		}
-	}
-
-	return true
+	return !b.hasLegalMoves()
 }
 
 func (b *Board) isGameOver() bool {
-	return b.finalTime.After(b.startTime)
+	return b.result != Ongoing
 }
 
-// `color` returns the color of the current active side.
-func (b *Board) color() PieceColor {
-	if b.isRedTurn {
-		return Red
+// Equal checks whether two boards have the same position, including
+// the side to move.
+func (b *Board) Equal(other *Board) bool {
+	if b.isRedTurn != other.isRedTurn {
+		return false
 	}
-	return Black
+	// The pieces of the same role might use different slots.
+	for i := 0; i <= 9; i++ {
+		for j := 0; j <= 8; j++ {
+			p1, ok1 := b.pieceAt(i, j)
+			p2, ok2 := other.pieceAt(i, j)
+			if ok1 != ok2 || p1 != p2 {
+				return false
+			}
+		}
+	}
+	return true
 }
@@ -1,124 +1,694 @@
 package rules
 
-import "time"
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
-func (b *Board) StartAI() {
-	b.isAIWorking = true
-	b.aiStartTime = time.Now()
+	"github.com/ahrtr/chess/utils"
+)
+
+// The search is stopped at this depth even if there is no other limit.
+const maxSearchDepth = 64
+
+// The main search never goes beyond this ply, even if it's extended.
+const maxPly = maxSearchDepth * 2
+
+// The pseudo-legal moves of any position are fewer than this, so the
+// buffers of the moves never grow.
+const maxMoves = 128
+
+// MateScore is the score of mating the opponent right now, and a mate in n
+// plies is scored MateScore - n, so the shorter mate is preferred. Any
+// score beyond mateThreshold is a mate.
+const (
+	MateScore     = 1000000
+	mateThreshold = MateScore - 1000
+	infinity      = MateScore + 1
+)
+
+// The aspiration window is searched from this depth, when the score of the
+// previous iteration is stable enough.
+const (
+	aspirationMinDepth = 4
+	aspirationWindow   = soldierValue / 2
+)
+
+// The margin of the delta pruning in the quiescence search, which covers
+// the positional gain of a capture.
+const deltaMargin = 2 * soldierValue
+
+// The null move search is reduced by nullMoveReduction plies besides the
+// null move itself, and it's only tried from nullMoveMinDepth, when the side
+// to move has at least nullMoveMinPieces rooks, horses and cannons.
+const (
+	nullMoveReduction = 2
+	nullMoveMinDepth  = 3
+	nullMoveMinPieces = 2
+)
+
+// The late quiet moves are reduced by one ply from lmrMinDepth, after the
+// first lmrMinMoves moves are searched, and by two plies after lmrLateMoves
+// moves at the nodes other than the PV nodes.
+const (
+	lmrMinDepth  = 3
+	lmrMinMoves  = 3
+	lmrLateMoves = 6
+)
+
+// The context is checked every so many nodes, because checking it involves
+// a lock.
+const checkInterval = 1024
+
+// SearchLimits bounds the search. The zero value of a field means no limit,
+// and the search stops as soon as any limit is reached.
+type SearchLimits struct {
+	// The maximum depth in plies.
+	Depth int
+	// The time to think.
+	Time time.Duration
+	// The maximum number of nodes to visit.
+	Nodes int
+}
+
+// SearchResult is the result of a search.
+type SearchResult struct {
+	// The best move, which is the zero Move if the side to move has no legal moves.
+	Move Move
+	// The score of the best move from the perspective of the side to move.
+	// A mate is scored MateScore minus the plies to the mate, see MateIn.
+	Score int
+	// The depth of the last completed iteration.
+	Depth int
+	// The principal variation, i.e. the best moves of both sides expected
+	// by the search, which starts with Move.
+	PV []Move
+	// The number of the nodes visited.
+	Nodes int
+	// The number of the beta cutoffs in the main search, and how many of
+	// them are caused by the first move searched, which tells how good the
+	// move ordering is.
+	Cutoffs          int
+	FirstMoveCutoffs int
+}
+
+// MateIn returns the number of the moves of the side to move to mate the
+// opponent, or the negative number of the moves to be mated, or 0 if no
+// mate is found.
+func (r SearchResult) MateIn() int {
+	switch {
+	case r.Score > mateThreshold:
+		return (MateScore - r.Score + 1) / 2
+	case r.Score < -mateThreshold:
+		return -(MateScore + r.Score + 1) / 2
+	}
+	return 0
+}
+
+// searcher carries the state of a search thread.
+type searcher struct {
+	b       *Board
+	ctx     context.Context
+	limits  SearchLimits
+	options EngineOptions
+	tt      *transpositionTable
+	order   moveOrder
+	// The number of the moves made before the search, which is used to
+	// tell the ply of a node.
+	rootPly int
+	// The triangular table of the principal variations: pv[ply] is the
+	// principal variation of the node at the ply, which is the moves
+	// pv[ply][ply:pvLength[ply]].
+	pv       [maxPly + 1][maxPly + 1]move
+	pvLength [maxPly + 1]int
+	// The buffers of the moves of the nodes at each ply, so the moves are
+	// generated without allocation, see moveBuffer.
+	moveBufs [maxPly + 1][maxMoves]move
+
+	nodes int
+	// The nodes visited by all the threads, to which each thread adds its
+	// own nodes every checkInterval nodes, and `published` is how many of
+	// the nodes of the thread have been added.
+	totalNodes *atomic.Int64
+	published  int
+
+	cutoffs          int
+	firstMoveCutoffs int
+	// Whether the search is stopped by the limits or the context, in which
+	// case the scores of the unfinished iteration can't be trusted.
+	stopped bool
+}
+
+// Search searches the best move by iterative deepening: it searches depth
+// 1, 2, 3 and so on until any limit is reached, and returns the best move
+// of the last completed iteration. Each iteration searches the best move
+// of the previous one first, so the best move found so far is returned if
+// the time runs out in the middle of an iteration.
+//
+// With more than one thread, the search is parallelized by Lazy SMP: the
+// helper threads search the same position on their own copies of the board
+// at the same time, and share the results through the transposition table,
+// so the main thread finds more cutoffs there and reaches deeper. Each
+// helper skips some depths by its own pattern (see helperDepthSkips), so
+// the helpers diverge from each other and from the main thread, and fill
+// the table with the deeper results ahead of it. The helpers stop once the
+// main thread is done, whose result is returned. With one thread, the
+// search is deterministic.
+//
+// The search stops as well once the context is canceled, e.g. when the
+// position has changed and the result is no longer wanted. The caller
+// should check ctx.Err() to tell whether the result is still wanted.
+//
+// The board is restored once the search returns, but it must not be changed
+// by anyone else during the search.
+func (e *Engine) Search(ctx context.Context, b *Board, limits SearchLimits) SearchResult {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if limits.Time > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.Time)
+		defer cancel()
+	}
+	moves := b.legalMoves(nil)
+	if len(moves) == 0 {
+		return SearchResult{}
+	}
+
+	var totalNodes atomic.Int64
+	newSearcher := func(ctx context.Context, b *Board) *searcher {
+		return &searcher{b: b, ctx: ctx, limits: limits, options: e.options, tt: e.tt,
+			rootPly: len(b.undoStack), totalNodes: &totalNodes}
+	}
+
+	helperCtx, stopHelpers := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	helpers := make([]*searcher, max(e.options.Threads, 1)-1)
+	for i := range helpers {
+		h, hMoves := newSearcher(helperCtx, b.Clone()), slices.Clone(moves)
+		helpers[i] = h
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.iterativeDeepening(hMoves, helperDepthSkips[i%len(helperDepthSkips)])
+		}()
+	}
+
+	s := newSearcher(ctx, b)
+	result := s.iterativeDeepening(moves, depthSkip{})
+	stopHelpers()
+	wg.Wait()
+
+	result.Nodes = int(totalNodes.Load())
+	result.Cutoffs, result.FirstMoveCutoffs = s.cutoffs, s.firstMoveCutoffs
+	for _, h := range helpers {
+		result.Cutoffs += h.cutoffs
+		result.FirstMoveCutoffs += h.firstMoveCutoffs
+	}
+	return result
+}
+
+// depthSkip is a pattern of the depths skipped by a helper thread of the
+// parallel search: the depths are grouped by `size` consecutive ones
+// starting from the offset `phase`, and every other group is skipped.
+type depthSkip struct {
+	size, phase int
 }
 
-func (b *Board) StopAI(hint string) {
-	b.isAIWorking = false
-	b.hintFromAI = hint
-	b.aiStopTime = time.Now()
+// helperDepthSkips are the patterns of the helper threads, the i-th helper
+// uses the i-th pattern (wrapping around). E.g. the first helper skips the
+// odd depths, the second skips the even ones, and the third skips 2, 3,
+// 6, 7 and so on.
+var helperDepthSkips = [...]depthSkip{
+	{1, 0}, {1, 1},
+	{2, 0}, {2, 1}, {2, 2}, {2, 3},
+	{3, 0}, {3, 1}, {3, 2}, {3, 3}, {3, 4}, {3, 5},
+	{4, 0}, {4, 1}, {4, 2}, {4, 3}, {4, 4}, {4, 5}, {4, 6}, {4, 7},
 }
 
-func (b *Board) resetAI() {
-	b.isAIWorking = false
-	b.hintFromAI = ""
-	b.aiStartTime = time.Now()
-	b.aiStopTime = time.Now()
+// skips checks whether the depth is skipped. The zero depthSkip never
+// skips any depth.
+func (d depthSkip) skips(depth int) bool {
+	return d.size > 0 && (depth+d.phase)/d.size%2 != 0
 }
 
-func (b *Board) GetBestMove(depth int) Move {
+// iterativeDeepening searches the root moves until any limit is reached,
+// except the depths skipped by `skip`, see Search. The nodes, the cutoffs
+// and the statistics of the result are left to the caller.
+func (s *searcher) iterativeDeepening(moves []move, skip depthSkip) SearchResult {
+	b := s.b
+	defer s.publishNodes()
+	maxDepth := maxSearchDepth
+	if s.limits.Depth > 0 {
+		maxDepth = min(s.limits.Depth, maxSearchDepth)
+	}
+	if s.options.MoveOrdering {
+		s.order.sort(&b.position, moves, 0, 0)
+	}
+
+	var result SearchResult
+	for depth := 1; depth <= maxDepth; depth++ {
+		if skip.skips(depth) {
+			continue
+		}
+		best, score := s.aspirationSearch(moves, depth, result.Score)
+		if s.stopped {
+			// The first move, which is the best one of the previous
+			// iteration, has been searched completely if any other move
+			// is found better, so the better one can be trusted.
+			if best != moves[0] {
+				result.Move, result.Score, result.PV = b.toMove(best), score, s.principalVariation()
+			}
+			break
+		}
+		result = SearchResult{Move: b.toMove(best), Score: score, Depth: depth, PV: s.principalVariation()}
+
+		// Search the best move first in the next iteration.
+		moveToFront(moves, best)
+	}
+	if result.Move == (Move{}) {
+		// Not even the first iteration is completed.
+		result.Move = b.toMove(moves[0])
+		result.PV = []Move{result.Move}
+	}
+	return result
+}
+
+// moveToFront moves mv to the front of the moves, and keeps the order of
+// the others.
+func moveToFront(moves []move, mv move) {
+	i := slices.Index(moves, mv)
+	copy(moves[1:i+1], moves[:i])
+	moves[0] = mv
+}
+
+// aspirationSearch searches the root moves to the depth with a narrow
+// window around the score of the previous iteration, which prunes much more
+// than the full window as long as the score doesn't change a lot. The
+// window is widened on the failing side and searched again otherwise.
+func (s *searcher) aspirationSearch(moves []move, depth, prevScore int) (move, int) {
+	alpha, beta := -infinity, infinity
+	window := aspirationWindow
+	if depth >= aspirationMinDepth && utils.Abs(prevScore) < mateThreshold {
+		alpha, beta = prevScore-window, prevScore+window
+	}
+	for {
+		best, score := s.searchRoot(moves, depth, alpha, beta)
+		if s.stopped {
+			return best, score
+		}
+		window *= 4
+		switch {
+		case score <= alpha:
+			alpha = max(score-window, -infinity)
+		case score >= beta:
+			beta = min(score+window, infinity)
+			// Search the move failing high first.
+			moveToFront(moves, best)
+		default:
+			return best, score
+		}
+	}
+}
+
+// searchRoot searches the root moves to the depth within the window, and
+// returns the best move and its score. The best move is moves[0] unless any
+// other move raises alpha, so if the search is stopped, the best move other
+// than moves[0] has been searched completely.
+func (s *searcher) searchRoot(moves []move, depth, alpha, beta int) (move, int) {
 	var (
-		bestMove  Move
-		bestScore = -1000000
-		color     = b.color()
+		b         = s.b
+		bestMove  = moves[0]
+		bestScore = -infinity
 	)
+	s.pvLength[0] = 0
+	for i, mv := range moves {
+		b.makeMove(mv)
+		score := s.searchMove(i, depth-1, alpha, beta, 0)
+		b.unmakeMove()
+		if s.stopped {
+			break
+		}
 
-	moves := b.validMoves()
-	for _, move := range moves {
-		cloneBoard := b.Clone()
-		cloneBoard.move(move.from.X, move.from.Y, move.to.X, move.to.Y, true)
+		bestScore = max(bestScore, score)
+		if score > alpha {
+			alpha = score
+			bestMove = mv
+			s.updatePV(0, mv)
+			if alpha >= beta {
+				break
+			}
+		}
+	}
+	return bestMove, bestScore
+}
 
-		score := minimax(cloneBoard, color, depth, -1000000, 1000000, false)
-		if score > bestScore {
-			bestScore = score
-			bestMove = move
+// searchMove searches the i-th move, which has been made, by the principal
+// variation search: the first move is searched with the full window, and
+// is expected to be the best one, so the other moves are searched with the
+// null window (alpha, alpha+1) to prove that they are worse, which is much
+// cheaper. Only the move which turns out better is searched again with the
+// full window. The score is from the perspective of the side which has
+// made the move.
+//
+// The null window search is reduced by `reduction` plies at first, and
+// searched again to the full depth if the move isn't proved worse.
+func (s *searcher) searchMove(i, depth, alpha, beta, reduction int) int {
+	if i == 0 {
+		return -s.negamax(depth, -beta, -alpha)
+	}
+	score := -s.negamax(depth-reduction, -alpha-1, -alpha)
+	if reduction > 0 && score > alpha {
+		score = -s.negamax(depth, -alpha-1, -alpha)
+	}
+	if score > alpha && score < beta {
+		score = -s.negamax(depth, -beta, -alpha)
+	}
+	return score
+}
+
+// shouldStop checks whether any limit is reached, or the context is done.
+func (s *searcher) shouldStop() bool {
+	if s.stopped {
+		return true
+	}
+	if s.nodes%checkInterval == 0 {
+		s.publishNodes()
+		if s.ctx.Err() != nil {
+			s.stopped = true
+			return true
 		}
 	}
+	if s.limits.Nodes > 0 && int(s.totalNodes.Load())+s.nodes-s.published >= s.limits.Nodes {
+		s.stopped = true
+	}
+	return s.stopped
+}
 
-	return bestMove
+// moveBuffer returns the empty buffer of the moves of the node at the ply.
+// The nodes of the quiescence search beyond maxPly, which are rare, don't
+// have any buffer, and allocate the moves instead.
+func (s *searcher) moveBuffer(ply int) []move {
+	if ply > maxPly {
+		return nil
+	}
+	return s.moveBufs[ply][:0]
 }
 
-// minimax tries to select the best policy to move. It simulates two
-// players to move in turn, and evaluate the score of each move.
-// Refer to
+// publishNodes adds the nodes visited since the last call to the nodes of
+// all the threads.
+func (s *searcher) publishNodes() {
+	s.totalNodes.Add(int64(s.nodes - s.published))
+	s.published = s.nodes
+}
+
+// negamax searches the position to the depth by the alpha-beta pruning, and
+// returns the score from the perspective of the side to move. Since the
+// score of one side is the negated score of the other, both sides maximize
+// their own scores, and the score of a move is the negated score of the
+// position after it.
 //
-//	-https://cs.stanford.edu/people/eroberts/courses/soco/projects/2003-04/intelligent-search/minimax.html.
-//	-https://cs.stanford.edu/people/eroberts/courses/soco/projects/2003-04/intelligent-search/alphabeta.html
+// The score is exact if it's inside the window (alpha, beta). Otherwise it's
+// an upper bound if it's at most alpha (fail low), or a lower bound if it's
+// at least beta (fail high), in which case the rest of the moves are pruned,
+// because the opponent won't allow the position anyway. Refer to
 //
-// Parameters:
-//   - b:     the board of the game
-//   - color: the color of the player against which the evaluation is performed
-//   - depth: the steps that we are trying to explore
-//   - alpha: the score of the best choice we have found so far at any choice point along the path for the maximizer (usually me)
-//   - beta:  the score of the best choice we have found so far at any choice point along the path for the minimizer (usually my opponent)
-//   - isMaximizing: which side to evaluate, the maximizer (true) or the minimizer (false)?
-func minimax(b *Board, color PieceColor, depth int, alpha, beta int, isMaximizing bool) int {
-	if depth == 0 || b.isGameOver() {
-		return evaluate(b, color)
-	}
-
-	moves := b.validMoves()
-
-	if isMaximizing {
-		// I am trying to maximize my score.
-		maxEval := -1000000
-		for _, m := range moves {
-			cloneBoard := b.Clone()
-			cloneBoard.move(m.from.X, m.from.Y, m.to.X, m.to.Y, true)
-
-			eval := minimax(cloneBoard, color, depth-1, alpha, beta, false)
-			maxEval = max(maxEval, eval)
-			alpha = max(alpha, eval)
-
-			// purge: The minimizer at the parent or at any choice point
-			// further up won't reach this node as it's greater than the
-			// best score that he/she is aware of.
-			if beta <= alpha {
-				break
+//	-https://www.chessprogramming.org/Negamax
+//	-https://www.chessprogramming.org/Alpha-Beta
+func (s *searcher) negamax(depth, alpha, beta int) int {
+	b := s.b
+	if s.shouldStop() {
+		// The score is discarded anyway.
+		return 0
+	}
+	ply := s.ply()
+	s.pvLength[ply] = ply
+	if depth <= 0 || ply >= maxPly {
+		return s.quiesce(alpha, beta)
+	}
+	s.nodes++
+
+	// Mate distance pruning: even a mate at this node can't be better than
+	// a shorter mate found already.
+	alpha = max(alpha, -MateScore+ply)
+	beta = min(beta, MateScore-ply-1)
+	if alpha >= beta {
+		return alpha
+	}
+
+	// The best move stored is searched first, even if the entry isn't
+	// deep enough to return the score. The score isn't returned at the PV
+	// nodes (with a full window), which would cut the principal variation.
+	pvNode := beta-alpha > 1
+	var ttMove move
+	if e, ok := s.tt.probe(b.hash); ok {
+		ttMove = e.move
+		score := scoreFromTT(int(e.score), ply)
+		switch {
+		case pvNode, int(e.depth) < depth:
+		case e.bound == boundExact,
+			e.bound == boundLower && score >= beta,
+			e.bound == boundUpper && score <= alpha:
+			return score
+		}
+	}
+	alphaOrig := alpha
+
+	inCheck := b.isInCheck(b.color())
+	if !pvNode && !inCheck && s.nullMovePrunes(depth, beta) {
+		return beta
+	}
+
+	moves := b.legalMoves(s.moveBuffer(ply))
+	if len(moves) == 0 {
+		// The side to move loses if there is no any valid move.
+		return -MateScore + ply
+	}
+	if s.options.MoveOrdering {
+		s.order.sort(&b.position, moves, ply, ttMove)
+	}
+
+	bestScore, bestMove := -infinity, move(0)
+	for i, mv := range moves {
+		quiet := b.squares[mv.to()] == 0
+		b.makeMove(mv)
+		givesCheck := b.isInCheck(b.color())
+		extension, reduction := 0, 0
+		if givesCheck && s.options.CheckExtensions {
+			extension = 1
+		}
+		if s.options.LateMoveReductions && quiet && !inCheck && !givesCheck &&
+			depth >= lmrMinDepth && i >= lmrMinMoves {
+			reduction = 1
+			if !pvNode && i >= lmrLateMoves {
+				reduction = 2
 			}
 		}
-		return maxEval
-	} else {
-		// My opponent is trying to minimize my score.
-		minEval := 1000000
-		for _, m := range moves {
-			cloneBoard := b.Clone()
-			cloneBoard.move(m.from.X, m.from.Y, m.to.X, m.to.Y, true)
-
-			eval := minimax(cloneBoard, color, depth-1, alpha, beta, true)
-			minEval = min(minEval, eval)
-			beta = min(beta, eval)
-
-			// purge: The maximizer at the parent or at any choice point
-			// further up definitely won't reach this node as it's less
-			// than the best score that he/she is aware of.
-			if beta <= alpha {
+		score := s.searchMove(i, depth-1+extension, alpha, beta, reduction)
+		b.unmakeMove()
+		if s.stopped {
+			return 0
+		}
+
+		if score > bestScore {
+			bestScore, bestMove = score, mv
+		}
+		if score > alpha {
+			alpha = score
+			s.updatePV(ply, mv)
+			if alpha >= beta {
+				s.cutoff(mv, i, ply, depth)
 				break
 			}
 		}
-		return minEval
 	}
+	s.storeTT(depth, bestScore, alphaOrig, beta, bestMove)
+	return bestScore
 }
 
-func evaluate(b *Board, color PieceColor) int {
-	score := 0
-	for i := 0; i <= 9; i++ {
-		for j := 0; j <= 8; j++ {
-			p := b.pieceMatrix[i][j]
-			if p == nil {
+// nullMovePrunes checks whether the node can be pruned by the null move:
+// if the opponent still can't reach beta after the side to move passes the
+// turn, which is searched to a reduced depth, any real move would be even
+// better, so the node is expected to fail high.
+//
+// The assumption is wrong in zugzwang, where any move makes the position
+// worse, which is common when there are few pieces left, so the null move
+// is only tried if the side to move has enough rooks, horses and cannons.
+// Two null moves in a row are never tried, which would simply reduce the
+// depth.
+func (s *searcher) nullMovePrunes(depth, beta int) bool {
+	b := s.b
+	if !s.options.NullMove || depth < nullMoveMinDepth || b.isAfterNullMove() ||
+		!b.hasNullMoveMaterial(b.color()) || s.options.Evaluator.Evaluate(b, b.color()) < beta {
+		return false
+	}
+	b.makeNullMove()
+	score := -s.negamax(depth-1-nullMoveReduction, -beta, -beta+1)
+	b.unmakeNullMove()
+	return !s.stopped && score >= beta
+}
+
+// hasNullMoveMaterial checks whether the color has enough pieces to try the
+// null move, i.e. it's unlikely to be in zugzwang.
+func (p *position) hasNullMoveMaterial(color PieceColor) bool {
+	n, tag := 0, sideTag(color)
+	for slot := tag; slot < tag+16; slot++ {
+		if p.pieces[slot] == 0 {
+			continue
+		}
+		switch slotRole(slot) {
+		case rookIndex, horseIndex, cannonIndex:
+			n++
+		}
+	}
+	return n >= nullMoveMinPieces
+}
+
+// quiesce extends the search at the horizon until the position is quiet,
+// so the score isn't evaluated in the middle of an exchange, e.g. right
+// after a rook captures a protected horse. Only the captures are searched,
+// unless the side to move is in check, in which case all the evasions are
+// searched.
+//
+// The side to move isn't forced to capture, so the static evaluation (the
+// stand pat) is a lower bound of the score if it's not in check. The
+// parameters and the score are the same as negamax.
+func (s *searcher) quiesce(alpha, beta int) int {
+	b := s.b
+	if s.shouldStop() {
+		return 0
+	}
+	s.nodes++
+
+	ply := s.ply()
+	inCheck := b.isInCheck(b.color())
+	var (
+		moves    []move
+		standPat int
+	)
+	if inCheck {
+		// The side to move is checkmated if it has no evasions.
+		moves = b.legalMoves(s.moveBuffer(ply))
+		standPat = -MateScore + ply
+	} else {
+		standPat = s.options.Evaluator.Evaluate(b, b.color())
+		if standPat >= beta {
+			return standPat
+		}
+		alpha = max(alpha, standPat)
+		moves = b.legalCaptures(s.moveBuffer(ply))
+	}
+	if s.options.MoveOrdering {
+		s.order.sort(&b.position, moves, ply, 0)
+	}
+
+	best := standPat
+	for _, mv := range moves {
+		if !inCheck && s.options.DeltaPruning {
+			// Even the captured piece for free plus a margin can't raise
+			// the score up to alpha.
+			gain := pieceValues[slotRole(int(b.squares[mv.to()]))]*soldierValue + deltaMargin
+			if standPat+gain <= alpha {
 				continue
 			}
-			if p.color == color {
-				score += pieceValueMap[p.role]
-			} else {
-				score -= pieceValueMap[p.role]
-			}
+		}
+
+		b.makeMove(mv)
+		score := -s.quiesce(-beta, -alpha)
+		b.unmakeMove()
+		if s.stopped {
+			return 0
+		}
+
+		best = max(best, score)
+		alpha = max(alpha, score)
+		if alpha >= beta {
+			break
 		}
 	}
+	return best
+}
 
+// ply returns the number of the moves made since the root.
+func (s *searcher) ply() int {
+	return len(s.b.undoStack) - s.rootPly
+}
+
+// updatePV sets the principal variation of the node at the ply to the move
+// followed by the principal variation of the child node.
+func (s *searcher) updatePV(ply int, mv move) {
+	s.pv[ply][ply] = mv
+	n := ply + 1
+	if n <= maxPly {
+		n = s.pvLength[ply+1]
+		copy(s.pv[ply][ply+1:n], s.pv[ply+1][ply+1:n])
+	}
+	s.pvLength[ply] = n
+}
+
+// principalVariation converts the principal variation of the root.
+func (s *searcher) principalVariation() []Move {
+	b := s.b
+	pv := make([]Move, 0, s.pvLength[0])
+	for _, mv := range s.pv[0][:s.pvLength[0]] {
+		pv = append(pv, b.toMove(mv))
+		b.makeMove(mv)
+	}
+	for range pv {
+		b.unmakeMove()
+	}
+	return pv
+}
+
+// cutoff records the move, which is the i-th move searched, causing a beta
+// cutoff at the ply with the remaining depth.
+func (s *searcher) cutoff(mv move, i, ply, depth int) {
+	s.cutoffs++
+	if i == 0 {
+		s.firstMoveCutoffs++
+	}
+	if s.options.MoveOrdering {
+		s.order.cutoff(&s.b.position, mv, ply, depth)
+	}
+}
+
+// storeTT stores the score of the current position searched with the
+// window (alpha, beta) into the transposition table.
+func (s *searcher) storeTT(depth, score, alpha, beta int, mv move) {
+	bound := boundExact
+	switch {
+	case score <= alpha:
+		bound = boundUpper
+	case score >= beta:
+		bound = boundLower
+	}
+	s.tt.store(s.b.hash, depth, scoreToTT(score, s.ply()), bound, mv)
+}
+
+// scoreToTT converts the score of a node at the ply to the score stored in
+// the transposition table. A mate score counts the plies from the root,
+// while the same position may be reached at another ply, so the mate
+// scores are stored relative to the node instead.
+func scoreToTT(score, ply int) int {
+	switch {
+	case score > mateThreshold:
+		return score + ply
+	case score < -mateThreshold:
+		return score - ply
+	}
+	return score
+}
+
+// scoreFromTT converts the score stored in the transposition table back to
+// the score of the node at the ply. See scoreToTT.
+func scoreFromTT(score, ply int) int {
+	switch {
+	case score > mateThreshold:
+		return score - ply
+	case score < -mateThreshold:
+		return score + ply
+	}
 	return score
 }
@@ -0,0 +1,294 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSearchLimits(t *testing.T) {
+	testCases := []struct {
+		name   string
+		limits SearchLimits
+	}{
+		{name: "depth", limits: SearchLimits{Depth: 3}},
+		{name: "nodes", limits: SearchLimits{Nodes: 5000}},
+		{name: "time", limits: SearchLimits{Time: 200 * time.Millisecond}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewBoard()
+			start := time.Now()
+			result := b.Search(context.Background(), tc.limits)
+			elapsed := time.Since(start)
+
+			if _, ok := b.FindMove(result.Move.From, result.Move.To); !ok {
+				t.Fatalf("Unexpected illegal move %s", result.Move)
+			}
+			if got := b.FEN(); got != StartFEN {
+				t.Errorf("The board isn't restored after the search, got: %s", got)
+			}
+			if tc.limits.Depth > 0 && result.Depth != tc.limits.Depth {
+				t.Errorf("Unexpected depth, want: %d, got: %d", tc.limits.Depth, result.Depth)
+			}
+			if tc.limits.Nodes > 0 && result.Nodes > tc.limits.Nodes {
+				t.Errorf("Too many nodes, limit: %d, got: %d", tc.limits.Nodes, result.Nodes)
+			}
+			if tc.limits.Time > 0 && elapsed > tc.limits.Time+time.Second {
+				t.Errorf("Too long to search, limit: %s, took: %s", tc.limits.Time, elapsed)
+			}
+		})
+	}
+}
+
+func TestSearchCapture(t *testing.T) {
+	// The red rook captures the unprotected black rook.
+	b, err := ParseFEN("4k4/9/9/9/4r4/9/9/9/4R4/3K5 w - - 0 1")
+	if err != nil {
+		t.Fatalf("Failed to parse the FEN: %v", err)
+	}
+	for depth := 1; depth <= 3; depth++ {
+		result := b.Search(context.Background(), SearchLimits{Depth: depth})
+		if got := result.Move.ICCS(); got != "e1e5" {
+			t.Errorf("Unexpected best move at depth %d, want: e1e5, got: %s", depth, got)
+		}
+	}
+}
+
+func TestGetBestMove(t *testing.T) {
+	b, err := ParseFEN("4k4/9/9/9/4r4/9/9/9/4R4/3K5 w - - 0 1")
+	if err != nil {
+		t.Fatalf("Failed to parse the FEN: %v", err)
+	}
+	// A depth less than 1 searches 1 ply, instead of the maximum depth.
+	for _, depth := range []int{-1, 0, 1} {
+		start := time.Now()
+		if got := b.GetBestMove(depth).ICCS(); got != "e1e5" {
+			t.Errorf("Unexpected best move at depth %d, want: e1e5, got: %s", depth, got)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Too long to search depth %d, took: %s", depth, elapsed)
+		}
+	}
+}
+
+func TestSearchAllocations(t *testing.T) {
+	// The moves of the nodes are generated and sorted in the buffers of the
+	// search, so the allocations don't grow with the nodes.
+	b := NewBoard()
+	e := NewEngine(DefaultEngineOptions)
+	var result SearchResult
+	allocs := testing.AllocsPerRun(1, func() {
+		result = e.Search(context.Background(), b, SearchLimits{Depth: 5})
+	})
+	if allocs > 100 {
+		t.Errorf("Too many allocations, got: %.0f for %d nodes", allocs, result.Nodes)
+	}
+}
+
+func TestSearchCanceled(t *testing.T) {
+	b := NewBoard()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	result := b.Search(ctx, SearchLimits{})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Too long to stop the canceled search, took: %s", elapsed)
+	}
+	if _, ok := b.FindMove(result.Move.From, result.Move.To); !ok {
+		t.Errorf("Unexpected illegal move %s", result.Move)
+	}
+	if got := b.FEN(); got != StartFEN {
+		t.Errorf("The board isn't restored after the search, got: %s", got)
+	}
+}
+
+func TestQuiescence(t *testing.T) {
+	// The black horse is protected by the black rook, so the red rook loses
+	// itself by capturing the horse, which is only seen by the quiescence
+	// search at depth 1.
+	b, err := ParseFEN("3k5/9/4r4/9/4n4/9/9/9/4R4/5K3 w - - 0 1")
+	if err != nil {
+		t.Fatalf("Failed to parse the FEN: %v", err)
+	}
+	for _, deltaPruning := range []bool{false, true} {
+		engine := NewEngine(EngineOptions{HashSize: 1, DeltaPruning: deltaPruning})
+		result := engine.Search(context.Background(), b, SearchLimits{Depth: 1})
+		if got := result.Move.ICCS(); got == "e1e5" {
+			t.Errorf("Unexpected capture of the protected horse (delta pruning: %t)", deltaPruning)
+		}
+	}
+}
+
+func TestPrincipalVariation(t *testing.T) {
+	for _, tc := range perftCases[:3] {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := ParseFEN(tc.fen)
+			if err != nil {
+				t.Fatalf("Failed to parse the FEN: %v", err)
+			}
+			result := b.Search(context.Background(), SearchLimits{Depth: 4})
+			if len(result.PV) == 0 || result.PV[0] != result.Move {
+				t.Fatalf("Unexpected principal variation %v of the best move %s", result.PV, result.Move)
+			}
+			// The principal variation is a sequence of the legal moves.
+			for _, m := range result.PV {
+				if err := b.ApplyMove(m); err != nil {
+					t.Fatalf("Unexpected illegal move %s in the principal variation: %v", m, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchMate(t *testing.T) {
+	// Red mates in 2 moves: the horse checks, and the rook mates.
+	b, err := ParseFEN("R1N1k1b2/9/3aba3/9/2nr5/2B6/9/4B4/4A4/4KA3 w - - 0 1")
+	if err != nil {
+		t.Fatalf("Failed to parse the FEN: %v", err)
+	}
+	for depth := 3; depth <= 5; depth++ {
+		result := b.Search(context.Background(), SearchLimits{Depth: depth})
+		if got := result.MateIn(); got != 2 {
+			t.Errorf("Unexpected mate at depth %d, want: 2, got: %d (score: %d)", depth, got, result.Score)
+		}
+		if len(result.PV) != 3 {
+			t.Errorf("Unexpected length of the principal variation at depth %d, want: 3, got: %d", depth, len(result.PV))
+		}
+	}
+
+	// Black is mated after the horse checks.
+	if err := b.ApplyMove(b.Search(context.Background(), SearchLimits{Depth: 3}).Move); err != nil {
+		t.Fatalf("Failed to apply the move: %v", err)
+	}
+	if got := b.Search(context.Background(), SearchLimits{Depth: 3}).MateIn(); got != -1 {
+		t.Errorf("Unexpected mate of Black, want: -1, got: %d", got)
+	}
+}
+
+func TestSelectiveSearch(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options func(*EngineOptions)
+	}{
+		{name: "all", options: func(*EngineOptions) {}},
+		{name: "no null move", options: func(o *EngineOptions) { o.NullMove = false }},
+		{name: "no late move reductions", options: func(o *EngineOptions) { o.LateMoveReductions = false }},
+		{name: "no check extensions", options: func(o *EngineOptions) { o.CheckExtensions = false }},
+	}
+	positions := []struct {
+		fen, move string
+	}{
+		// The red rook captures the unprotected black rook.
+		{"4k4/9/9/9/4r4/9/9/9/4R4/3K5 w - - 0 1", "e1e5"},
+		// Red mates in 2 moves.
+		{"R1N1k1b2/9/3aba3/9/2nr5/2B6/9/4B4/4A4/4KA3 w - - 0 1", "c9d7"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := DefaultEngineOptions
+			tc.options(&options)
+			for _, p := range positions {
+				b, err := ParseFEN(p.fen)
+				if err != nil {
+					t.Fatalf("Failed to parse the FEN: %v", err)
+				}
+				result := NewEngine(options).Search(context.Background(), b, SearchLimits{Depth: 5})
+				if got := result.Move.ICCS(); got != p.move {
+					t.Errorf("Unexpected best move of %s, want: %s, got: %s", p.fen, p.move, got)
+				}
+				if got := b.FEN(); got != p.fen {
+					t.Errorf("The board isn't restored after the search, got: %s", got)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectiveSearchPruning(t *testing.T) {
+	b := NewBoard()
+	full := DefaultEngineOptions
+	full.NullMove, full.LateMoveReductions, full.CheckExtensions = false, false, false
+
+	selective := NewEngine(DefaultEngineOptions).Search(context.Background(), b, SearchLimits{Depth: 5})
+	fullWidth := NewEngine(full).Search(context.Background(), b, SearchLimits{Depth: 5})
+	if selective.Nodes >= fullWidth.Nodes {
+		t.Errorf("Expected the selective search to visit fewer nodes, with: %d, without: %d", selective.Nodes, fullWidth.Nodes)
+	}
+}
+
+func TestNullMove(t *testing.T) {
+	b := NewBoard()
+	hash := b.Hash()
+	b.makeNullMove()
+	if b.color() != Black || !b.isAfterNullMove() {
+		t.Fatal("Expected Black to move after the null move")
+	}
+	if b.Hash() != b.computeHash() {
+		t.Errorf("Unexpected hash after the null move, want: %x, got: %x", b.computeHash(), b.Hash())
+	}
+	b.unmakeNullMove()
+	if got := b.FEN(); got != StartFEN || b.Hash() != hash {
+		t.Errorf("The board isn't restored after the null move, got: %s", got)
+	}
+
+	// Only the soldiers and the defenders are prone to zugzwang.
+	b, err := ParseFEN("3k5/4a4/9/4p4/9/9/4P4/9/4A4/4KR3 w - - 0 1")
+	if err != nil {
+		t.Fatalf("Failed to parse the FEN: %v", err)
+	}
+	if b.hasNullMoveMaterial(Red) || b.hasNullMoveMaterial(Black) {
+		t.Error("Unexpected null move in the endgame with few pieces")
+	}
+	if !NewBoard().hasNullMoveMaterial(Red) {
+		t.Error("Expected the null move in the start position")
+	}
+}
+
+func TestParallelSearch(t *testing.T) {
+	b := NewBoard()
+	search := func(threads int) SearchResult {
+		options := DefaultEngineOptions
+		options.Threads = threads
+		return NewEngine(options).Search(context.Background(), b, SearchLimits{Depth: 5})
+	}
+
+	// The single thread search is reproducible.
+	first, second := search(1), search(1)
+	if first.Move != second.Move || first.Score != second.Score || first.Nodes != second.Nodes {
+		t.Errorf("Unexpected different results of the single thread, first: %+v, second: %+v", first, second)
+	}
+
+	result := search(4)
+	if _, ok := b.FindMove(result.Move.From, result.Move.To); !ok {
+		t.Fatalf("Unexpected illegal move %s", result.Move)
+	}
+	if result.Depth != 5 {
+		t.Errorf("Unexpected depth, want: 5, got: %d", result.Depth)
+	}
+	if got := b.FEN(); got != StartFEN {
+		t.Errorf("The board isn't restored after the search, got: %s", got)
+	}
+}
+
+func TestSearchMoveEqual(t *testing.T) {
+	// The capture moves are comparable, no matter where they come from.
+	b, err := ParseFEN("4k4/9/9/9/4r4/9/9/9/4R4/3K5 w - - 0 1")
+	if err != nil {
+		t.Fatalf("Failed to parse the FEN: %v", err)
+	}
+	want, err := b.ParseICCS("e1e5")
+	if err != nil {
+		t.Fatalf("Failed to parse the move: %v", err)
+	}
+	if !want.IsCapture() {
+		t.Fatalf("Expected %s to be a capture", want)
+	}
+	result := b.Search(context.Background(), SearchLimits{Depth: 3})
+	if result.Move != want || result.PV[0] != want {
+		t.Errorf("Unexpected best move, want: %+v, got: %+v (pv: %+v)", want, result.Move, result.PV)
+	}
+}
@@ -0,0 +1,85 @@
+package rules
+
+import (
+	"image"
+	"testing"
+)
+
+func TestPieceAt(t *testing.T) {
+	b := NewBoard()
+	testCases := []struct {
+		pt   image.Point
+		want *Piece
+	}{
+		{image.Point{X: 0, Y: 0}, &Piece{Black, RoleRook}},
+		{image.Point{X: 9, Y: 4}, &Piece{Red, RoleKing}},
+		{image.Point{X: 7, Y: 1}, &Piece{Red, RoleCannon}},
+		{image.Point{X: 4, Y: 4}, nil},
+		// off the board, which must not wrap around to another square
+		{image.Point{X: 0, Y: 16}, nil},
+		{image.Point{X: 0, Y: 9}, nil},
+		{image.Point{X: 10, Y: 0}, nil},
+		{image.Point{X: -1, Y: 0}, nil},
+		{image.Point{X: 1, Y: -1}, nil},
+	}
+	for _, tc := range testCases {
+		got := b.PieceAt(tc.pt)
+		if (got == nil) != (tc.want == nil) || got != nil && *got != *tc.want {
+			t.Errorf("Unexpected piece at %v, want: %v, got: %v", tc.pt, tc.want, got)
+		}
+	}
+}
+
+func TestBoardEqual(t *testing.T) {
+	play := func(fen string, moves ...string) *Board {
+		t.Helper()
+		b, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf("Failed to parse the FEN: %v", err)
+		}
+		for _, s := range moves {
+			m, err := b.ParseICCS(s)
+			if err != nil {
+				t.Fatalf("Failed to parse %s: %v", s, err)
+			}
+			if err := b.ApplyMove(m); err != nil {
+				t.Fatalf("Failed to apply %s: %v", s, err)
+			}
+		}
+		return b
+	}
+
+	// The red rooks swap their squares, so each of them is in the slot of
+	// the other one, compared to the position parsed from the FEN.
+	const fen = "3k5/9/9/9/9/9/9/9/1R7/R3K4 w - - 0 1"
+	swapped := play(fen, "b1b2", "d9d8", "a0a1", "d8d9", "a1b1", "d9d8", "b2a2", "d8d9", "a2a0")
+	parsed := play("3k5/9/9/9/9/9/9/9/1R7/R3K4 b - - 9 5")
+	if !swapped.Equal(parsed) || !parsed.Equal(swapped) {
+		t.Errorf("Expected the same position, got: %s and %s", swapped.FEN(), parsed.FEN())
+	}
+	// The encoding is canonical, no matter how the position is reached.
+	if got, want := swapped.FEN(), parsed.FEN(); got != want {
+		t.Errorf("Unexpected FEN, want: %s, got: %s", want, got)
+	}
+	if swapped.Hash() != parsed.Hash() {
+		t.Errorf("Unexpected hash, want: %x, got: %x", parsed.Hash(), swapped.Hash())
+	}
+	if clone := swapped.Clone(); !clone.Equal(swapped) {
+		t.Errorf("Expected the clone to be the same position, got: %s", clone.FEN())
+	}
+
+	// The side to move, or any piece on a different point, differs.
+	for _, other := range []*Board{
+		play("3k5/9/9/9/9/9/9/9/1R7/R3K4 w - - 0 1"),
+		play(fen, "b1b2"),
+		play("3k5/9/9/9/9/9/9/9/1R7/R4K3 b - - 0 1"),
+		play("3k5/9/9/9/9/9/9/9/1C7/R3K4 b - - 0 1"),
+	} {
+		if swapped.Equal(other) {
+			t.Errorf("Unexpected same position of %s and %s", swapped.FEN(), other.FEN())
+		}
+	}
+	if !NewBoard().Equal(play(StartFEN)) {
+		t.Error("Expected the new board to be the start position")
+	}
+}
@@ -0,0 +1,78 @@
+package rules
+
+// DrawRules configures when the game is drawn, besides the repetitions.
+type DrawRules struct {
+	// The game is drawn after the number of full moves (2 half moves each)
+	// without any capture. 0 disables the rule.
+	MoveLimit int
+	// The game is drawn when neither side has any attacking piece, which
+	// is a rook, a horse, a cannon or a soldier that hasn't reached the
+	// opponent's back rank. A soldier at home still counts, since it can
+	// cross the river later.
+	InsufficientMaterial bool
+}
+
+// DefaultDrawRules is the natural move limit of 60 moves (120 half moves),
+// and the draw by insufficient material.
+var DefaultDrawRules = DrawRules{
+	MoveLimit:            60,
+	InsufficientMaterial: true,
+}
+
+// SetDrawRules sets the rules to draw the game, which defaults to
+// DefaultDrawRules. It's supposed to be called before the game starts,
+// and the result of the current position is evaluated again.
+func (b *Board) SetDrawRules(r DrawRules) {
+	b.drawRules = r
+	b.result, b.reason = Ongoing, ""
+	b.updateResult()
+}
+
+// AgreeDraw ends the game in a draw agreed by both sides. It does nothing
+// if the game is already over.
+func (b *Board) AgreeDraw() {
+	b.end(Draw, ReasonAgreement)
+}
+
+// drawReason checks whether the game is drawn by the draw rules, and
+// returns the reason, or empty if it isn't drawn.
+func (b *Board) drawReason() Reason {
+	if b.drawRules.MoveLimit > 0 && b.halfMoveClock >= b.drawRules.MoveLimit*2 {
+		return ReasonMoveLimit
+	}
+	if b.drawRules.InsufficientMaterial && !b.hasAttackingPieces(Red) && !b.hasAttackingPieces(Black) {
+		return ReasonInsufficientMaterial
+	}
+	return ""
+}
+
+// hasAttackingPieces checks whether the color has any piece which can
+// attack the opponent's king.
+func (p *position) hasAttackingPieces(color PieceColor) bool {
+	tag := sideTag(color)
+	for slot := tag; slot < tag+16; slot++ {
+		sq := square(p.pieces[slot])
+		if sq == 0 {
+			continue
+		}
+		switch slotRole(slot) {
+		case rookIndex, horseIndex, cannonIndex:
+			return true
+		case soldierIndex:
+			// A soldier on the back rank can only move sideways there,
+			// which can't mate the king alone.
+			if !isBackRank(rowOf(sq), opponent(color)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isBackRank tells whether the row is the back rank of the color.
+func isBackRank(row int, color PieceColor) bool {
+	if color == Red {
+		return row == 9
+	}
+	return row == 0
+}
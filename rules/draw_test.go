@@ -0,0 +1,105 @@
+package rules
+
+import "testing"
+
+func TestDrawRules(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fen   string
+		rules DrawRules
+		moves []string
+		drawn bool
+	}{
+		{
+			name:  "move limit reached",
+			fen:   "4k4/9/9/9/9/9/9/9/R8/5K3 w - - 119 70",
+			rules: DefaultDrawRules,
+			moves: []string{"a1b1"},
+			drawn: true,
+		},
+		{
+			name:  "move limit not reached",
+			fen:   "4k4/9/9/9/9/9/9/9/R8/5K3 w - - 118 70",
+			rules: DefaultDrawRules,
+			moves: []string{"a1b1"},
+		},
+		{
+			name:  "move limit disabled",
+			fen:   "4k4/9/9/9/9/9/9/9/R8/5K3 w - - 119 70",
+			rules: DrawRules{InsufficientMaterial: true},
+			moves: []string{"a1b1"},
+		},
+		{
+			name:  "no attacking pieces",
+			fen:   "3ak4/4a4/4b4/9/9/9/9/4B4/4A4/3AK4 w - - 0 1",
+			rules: DefaultDrawRules,
+			drawn: true,
+		},
+		{
+			name:  "insufficient material disabled",
+			fen:   "3ak4/4a4/4b4/9/9/9/9/4B4/4A4/3AK4 w - - 0 1",
+			rules: DrawRules{MoveLimit: 60},
+		},
+		{
+			name:  "soldier at home",
+			fen:   "3ak4/9/9/9/9/4P4/9/9/9/3K5 w - - 0 1",
+			rules: DefaultDrawRules,
+		},
+		{
+			name:  "soldiers at home",
+			fen:   "3k5/9/9/9/9/2P1P4/2P1P4/2P6/9/4K4 w - - 0 1",
+			rules: DefaultDrawRules,
+		},
+		{
+			name:  "soldier on the back rank",
+			fen:   "3ak1P2/9/9/9/9/9/9/9/9/3K5 w - - 0 1",
+			rules: DefaultDrawRules,
+			drawn: true,
+		},
+		{
+			name:  "soldier across the river",
+			fen:   "3ak4/9/9/9/4P4/9/9/9/9/3K5 w - - 0 1",
+			rules: DefaultDrawRules,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := ParseFEN(tc.fen)
+			if err != nil {
+				t.Fatalf("Failed to parse the FEN: %v", err)
+			}
+			b.SetDrawRules(tc.rules)
+			for _, s := range tc.moves {
+				m, err := b.ParseICCS(s)
+				if err != nil {
+					t.Fatalf("Failed to parse %s: %v", s, err)
+				}
+				if err := b.ApplyMove(m); err != nil {
+					t.Fatalf("Failed to apply %s: %v", s, err)
+				}
+			}
+
+			if b.IsGameOver() != tc.drawn {
+				t.Fatalf("Unexpected game over, want: %t, got: %t", tc.drawn, b.IsGameOver())
+			}
+			if winner, ok := b.Winner(); ok {
+				t.Errorf("Unexpected winner %s", winner)
+			}
+		})
+	}
+}
+
+func TestAgreeDraw(t *testing.T) {
+	b := NewBoard()
+	b.AgreeDraw()
+	if !b.IsGameOver() {
+		t.Fatal("Expected the game to be over")
+	}
+	if winner, ok := b.Winner(); ok {
+		t.Errorf("Unexpected winner %s", winner)
+	}
+	if moves := b.LegalMoves(); len(moves) != 0 {
+		t.Errorf("Expected no legal moves, got: %d", len(moves))
+	}
+}
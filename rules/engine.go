@@ -0,0 +1,90 @@
+package rules
+
+import (
+	"context"
+	"sync"
+)
+
+// EngineOptions configures the engine.
+type EngineOptions struct {
+	// The size of the transposition table in MB.
+	HashSize int
+	// Whether to skip the captures which can't raise the score up to alpha
+	// in the quiescence search, even if the captured piece is free.
+	DeltaPruning bool
+	// Whether to order the moves (see moveOrder), which is only turned off
+	// to measure how much it improves the pruning.
+	MoveOrdering bool
+	// Whether to prune a node if passing the turn to the opponent still
+	// fails high in a reduced search (see nullMoveReduction).
+	NullMove bool
+	// Whether to search the late quiet moves to a reduced depth first,
+	// which are unlikely to be the best ones if the moves are well ordered.
+	LateMoveReductions bool
+	// Whether to search one ply deeper after a check, so a mating attack
+	// isn't cut off at the horizon.
+	CheckExtensions bool
+	// The number of the threads to search in parallel, which defaults to 1
+	// if not positive. See Engine.Search.
+	Threads int
+	// The evaluator of the positions, which defaults to PositionalEvaluator
+	// if nil.
+	Evaluator Evaluator
+}
+
+// DefaultEngineOptions is used by Board.Search and GetBestMove, except the
+// size of the transposition table.
+var DefaultEngineOptions = EngineOptions{
+	HashSize:           16,
+	DeltaPruning:       true,
+	MoveOrdering:       true,
+	NullMove:           true,
+	LateMoveReductions: true,
+	CheckExtensions:    true,
+	Threads:            1,
+	Evaluator:          PositionalEvaluator{},
+}
+
+// Engine searches the best moves. It keeps what it has learned, e.g. the
+// transposition table, from one search to the next, so it should be reused
+// for the searches of the same game.
+type Engine struct {
+	options EngineOptions
+	tt      *transpositionTable
+	// Serializes the searches, each of which may run several threads
+	// sharing the transposition table. A new search waits for the canceled
+	// one to return.
+	mu sync.Mutex
+}
+
+func NewEngine(options EngineOptions) *Engine {
+	if options.Evaluator == nil {
+		options.Evaluator = PositionalEvaluator{}
+	}
+	return &Engine{
+		options: options,
+		tt:      newTranspositionTable(options.HashSize),
+	}
+}
+
+// The size of the transposition table in MB of the fresh engine of each
+// Board.Search, which is thrown away after the search. Allocating and
+// clearing the 16 MB table of DefaultEngineOptions costs more than a
+// shallow search, while a deep search should reuse an Engine anyway.
+const oneOffHashSize = 1
+
+// Search searches the best move of the board with a fresh engine of the
+// default options, except a small transposition table (see oneOffHashSize).
+// See Engine.Search for the details.
+func (b *Board) Search(ctx context.Context, limits SearchLimits) SearchResult {
+	options := DefaultEngineOptions
+	options.HashSize = oneOffHashSize
+	return NewEngine(options).Search(ctx, b, limits)
+}
+
+// GetBestMove searches the specified depth, and returns the best move. The
+// depth is at least 1, because the search of SearchLimits without any limit
+// runs until the maximum depth.
+func (b *Board) GetBestMove(depth int) Move {
+	return b.Search(context.Background(), SearchLimits{Depth: max(depth, 1)}).Move
+}
@@ -0,0 +1,273 @@
+package rules
+
+// phaseScore is a pair of scores, one for the middlegame and the other for
+// the endgame, which are interpolated by the phase of the game.
+type phaseScore struct {
+	mg, eg int
+}
+
+func (s *phaseScore) add(o phaseScore) {
+	s.mg += o.mg
+	s.eg += o.eg
+}
+
+func (s *phaseScore) sub(o phaseScore) {
+	s.mg -= o.mg
+	s.eg -= o.eg
+}
+
+func (s phaseScore) times(n int) phaseScore {
+	return phaseScore{s.mg * n, s.eg * n}
+}
+
+var (
+	// The material of each role. The horse is stronger in the endgame, when
+	// there are fewer pieces to block its legs, while the cannon is weaker,
+	// when there are fewer pieces to use as the platforms.
+	materialScores = [7]phaseScore{
+		kingIndex:    {0, 0},
+		guardIndex:   {200, 180},
+		bishopIndex:  {200, 180},
+		horseIndex:   {450, 500},
+		rookIndex:    {1000, 1050},
+		cannonIndex:  {480, 420},
+		soldierIndex: {100, 120},
+	}
+
+	// The bonus of each pseudo-legal move of the piece.
+	mobilityScores = [7]phaseScore{
+		horseIndex:  {8, 10},
+		rookIndex:   {4, 6},
+		cannonIndex: {3, 2},
+	}
+
+	// A soldier having crossed the river can move sideways, and attack the
+	// king together with the other pieces.
+	crossedSoldierScore = phaseScore{50, 100}
+
+	// A rook on a file without any soldier (open), or without any own
+	// soldier (half-open).
+	rookOpenFileScore     = phaseScore{30, 15}
+	rookHalfOpenFileScore = phaseScore{15, 10}
+
+	// The penalty of each missing guard and bishop, when the opponent has
+	// the maximum number of attacking pieces (kingSafetyAttackers).
+	missingGuardScore  = phaseScore{40, 30}
+	missingBishopScore = phaseScore{30, 30}
+)
+
+// The penalty of the missing guards and bishops grows with the number of
+// the attacking pieces of the opponent up to this number.
+const kingSafetyAttackers = 4
+
+// The phase of the game is counted by the rooks, the horses and the cannons
+// on the board: maxPhase at the start, and 0 when all of them are gone.
+var phaseWeights = [7]int{
+	horseIndex:  1,
+	rookIndex:   2,
+	cannonIndex: 1,
+}
+
+const maxPhase = 16
+
+// The piece-square tables are the bonuses of each role on each point from
+// the perspective of Red, i.e. the row 0 is Black's back rank, and the row 9
+// is Red's. The tables of Black are mirrored vertically.
+var (
+	kingTable = [10][9]int{
+		7: {0, 0, 0, -15, -20, -15, 0, 0, 0},
+		8: {0, 0, 0, -5, -10, -5, 0, 0, 0},
+		9: {0, 0, 0, 5, 10, 5, 0, 0, 0},
+	}
+	guardTable = [10][9]int{
+		7: {0, 0, 0, -5, 0, -5, 0, 0, 0},
+		8: {0, 0, 0, 0, 5, 0, 0, 0, 0},
+		9: {0, 0, 0, 0, 0, 0, 0, 0, 0},
+	}
+	bishopTable = [10][9]int{
+		5: {0, 0, -5, 0, 0, 0, -5, 0, 0},
+		7: {-5, 0, 0, 0, 5, 0, 0, 0, -5},
+		9: {0, 0, 0, 0, 0, 0, 0, 0, 0},
+	}
+	horseTable = [10][9]int{
+		{-10, 0, 10, 10, 0, 10, 10, 0, -10},
+		{0, 20, 40, 30, 10, 30, 40, 20, 0},
+		{10, 30, 40, 50, 40, 50, 40, 30, 10},
+		{10, 30, 40, 50, 50, 50, 40, 30, 10},
+		{0, 20, 30, 40, 40, 40, 30, 20, 0},
+		{0, 10, 20, 30, 30, 30, 20, 10, 0},
+		{-10, 10, 20, 20, 20, 20, 20, 10, -10},
+		{-10, 0, 10, 10, 10, 10, 10, 0, -10},
+		{-20, -10, 0, 0, -20, 0, 0, -10, -20},
+		{-30, -20, -10, -10, -20, -10, -10, -20, -30},
+	}
+	rookTable = [10][9]int{
+		{10, 20, 10, 30, 30, 30, 10, 20, 10},
+		{20, 30, 20, 40, 50, 40, 20, 30, 20},
+		{10, 20, 10, 30, 30, 30, 10, 20, 10},
+		{10, 30, 20, 40, 40, 40, 20, 30, 10},
+		{10, 30, 20, 30, 30, 30, 20, 30, 10},
+		{0, 20, 10, 20, 20, 20, 10, 20, 0},
+		{0, 10, 0, 10, 10, 10, 0, 10, 0},
+		{-10, 10, 0, 10, 0, 10, 0, 10, -10},
+		{-10, 10, 0, 10, 0, 10, 0, 10, -10},
+		{-20, 10, 0, 10, 0, 10, 0, 10, -20},
+	}
+	cannonTable = [10][9]int{
+		{20, 10, 0, -10, -10, -10, 0, 10, 20},
+		{10, 10, 0, -10, -10, -10, 0, 10, 10},
+		{10, 10, 0, -10, 0, -10, 0, 10, 10},
+		{0, 0, 0, 0, 10, 0, 0, 0, 0},
+		{0, 0, 0, 0, 10, 0, 0, 0, 0},
+		{0, 0, 10, 0, 20, 0, 10, 0, 0},
+		{0, 0, 0, 0, 10, 0, 0, 0, 0},
+		{10, 10, 10, 20, 40, 20, 10, 10, 10},
+		{0, 10, 10, 10, 20, 10, 10, 10, 0},
+		{0, 0, 10, 20, 20, 20, 10, 0, 0},
+	}
+	soldierTable = [10][9]int{
+		{0, 0, 0, 5, 10, 5, 0, 0, 0},
+		{20, 30, 45, 55, 60, 55, 45, 30, 20},
+		{20, 30, 40, 50, 55, 50, 40, 30, 20},
+		{15, 25, 30, 40, 45, 40, 30, 25, 15},
+		{5, 10, 15, 20, 25, 20, 15, 10, 5},
+		{0, 0, 0, 0, 10, 0, 0, 0, 0},
+		{0, 0, 0, 0, 5, 0, 0, 0, 0},
+	}
+
+	// pieceSquareScores is the material plus the bonus of the tables of
+	// each piece (see pieceIndex) on each square.
+	pieceSquareScores [14][256]phaseScore
+)
+
+func init() {
+	tables := [7]*[10][9]int{
+		kingIndex:    &kingTable,
+		guardIndex:   &guardTable,
+		bishopIndex:  &bishopTable,
+		horseIndex:   &horseTable,
+		rookIndex:    &rookTable,
+		cannonIndex:  &cannonTable,
+		soldierIndex: &soldierTable,
+	}
+	for role, table := range tables {
+		for i := 0; i <= 9; i++ {
+			for j := 0; j <= 8; j++ {
+				red, black := materialScores[role], materialScores[role]
+				red.add(phaseScore{table[i][j], table[i][j]})
+				black.add(phaseScore{table[9-i][j], table[9-i][j]})
+				pieceSquareScores[role][toSquare(i, j)] = red
+				pieceSquareScores[role+7][toSquare(i, j)] = black
+			}
+		}
+	}
+}
+
+// sideEvaluation is what's evaluated of the pieces of one side.
+type sideEvaluation struct {
+	score phaseScore
+	// the number of the guards and the bishops defending the king
+	guards, bishops int
+	// the number of the pieces which can attack the opponent's king
+	attackers int
+	// the contribution to the phase of the game
+	phase int
+}
+
+// PositionalEvaluator evaluates the material by the piece-square tables,
+// and the positional terms: the mobility, the king safety, the crossed
+// soldiers and the rooks on the open files. Each term has a middlegame and
+// an endgame score, which are interpolated by the phase of the game.
+type PositionalEvaluator struct{}
+
+func (PositionalEvaluator) Evaluate(b *Board, color PieceColor) int {
+	red, black := b.evaluateSide(Red), b.evaluateSide(Black)
+	red.score.sub(red.kingSafetyPenalty(black.attackers))
+	black.score.sub(black.kingSafetyPenalty(red.attackers))
+
+	score := red.score
+	score.sub(black.score)
+	phase := min(red.phase+black.phase, maxPhase)
+	total := (score.mg*phase + score.eg*(maxPhase-phase)) / maxPhase
+	if color == Black {
+		return -total
+	}
+	return total
+}
+
+// evaluateSide evaluates the pieces of the color, except the king safety,
+// which depends on the opponent's pieces.
+func (p *position) evaluateSide(color PieceColor) sideEvaluation {
+	var (
+		e   sideEvaluation
+		tag = sideTag(color)
+		ci  = colorIndex(color)
+		// large enough for the moves of any piece, so it's never allocated
+		buf [32]move
+	)
+	for slot := tag; slot < tag+16; slot++ {
+		sq := square(p.pieces[slot])
+		if sq == 0 {
+			continue
+		}
+		role := slotRole(slot)
+		e.score.add(pieceSquareScores[pieceIndex(slot)][sq])
+		e.phase += phaseWeights[role]
+
+		switch role {
+		case guardIndex:
+			e.guards++
+		case bishopIndex:
+			e.bishops++
+		case horseIndex, rookIndex, cannonIndex:
+			e.attackers++
+			moves := p.generatePieceMoves(slot, sq, buf[:0])
+			e.score.add(mobilityScores[role].times(len(moves)))
+			if role == rookIndex {
+				e.score.add(p.rookFileScore(colOf(sq), color))
+			}
+		case soldierIndex:
+			if !ownSide[ci][sq] {
+				e.attackers++
+				e.score.add(crossedSoldierScore)
+			}
+		}
+	}
+	return e
+}
+
+// kingSafetyPenalty returns the penalty of the missing guards and bishops
+// against the number of the opponent's attacking pieces.
+func (e sideEvaluation) kingSafetyPenalty(attackers int) phaseScore {
+	attackers = min(attackers, kingSafetyAttackers)
+	penalty := missingGuardScore.times(2 - e.guards)
+	penalty.add(missingBishopScore.times(2 - e.bishops))
+	return phaseScore{
+		penalty.mg * attackers / kingSafetyAttackers,
+		penalty.eg * attackers / kingSafetyAttackers,
+	}
+}
+
+// rookFileScore returns the bonus of a rook of the color on the column,
+// depending on the soldiers on the same file.
+func (p *position) rookFileScore(col int, color PieceColor) phaseScore {
+	own, opponent := false, false
+	for row := 0; row <= 9; row++ {
+		slot := int(p.squares[toSquare(row, col)])
+		if slot == 0 || slotRole(slot) != soldierIndex {
+			continue
+		}
+		if slotColor(slot) == color {
+			own = true
+		} else {
+			opponent = true
+		}
+	}
+	switch {
+	case own:
+		return phaseScore{}
+	case opponent:
+		return rookHalfOpenFileScore
+	}
+	return rookOpenFileScore
+}
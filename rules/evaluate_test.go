@@ -0,0 +1,127 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+var positional PositionalEvaluator
+
+// mirrorFEN swaps the colors of the position, i.e. flips the board
+// vertically, swaps the cases of the pieces and the side to move.
+func mirrorFEN(fen string) string {
+	fields := strings.Fields(fen)
+	ranks := strings.Split(fields[0], "/")
+	for i, j := 0, len(ranks)-1; i < j; i, j = i+1, j-1 {
+		ranks[i], ranks[j] = ranks[j], ranks[i]
+	}
+	fields[0] = strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, strings.Join(ranks, "/"))
+	if fields[1] == "w" {
+		fields[1] = "b"
+	} else {
+		fields[1] = "w"
+	}
+	return strings.Join(fields, " ")
+}
+
+func TestEvaluateSymmetry(t *testing.T) {
+	for _, tc := range perftCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := ParseFEN(tc.fen)
+			if err != nil {
+				t.Fatalf("Failed to parse the FEN: %v", err)
+			}
+			mirrored, err := ParseFEN(mirrorFEN(tc.fen))
+			if err != nil {
+				t.Fatalf("Failed to parse the mirrored FEN: %v", err)
+			}
+
+			red, black := positional.Evaluate(b, Red), positional.Evaluate(b, Black)
+			if red != -black {
+				t.Errorf("Unexpected asymmetric scores, red: %d, black: %d", red, black)
+			}
+			if got := positional.Evaluate(mirrored, Black); got != red {
+				t.Errorf("Unexpected score of the mirrored position, want: %d, got: %d", red, got)
+			}
+		})
+	}
+
+	if got := positional.Evaluate(NewBoard(), Red); got != 0 {
+		t.Errorf("Unexpected score of the start position, want: 0, got: %d", got)
+	}
+}
+
+func TestEvaluatePosition(t *testing.T) {
+	// Each case is a pair of the positions of the same material, and the
+	// first one is better for Red.
+	testCases := []struct {
+		name          string
+		better, worse string
+	}{
+		{
+			name:   "crossed soldier",
+			better: "3k5/9/9/4P4/9/9/9/9/9/4K4 w - - 0 1",
+			worse:  "3k5/9/9/9/9/9/4P4/9/9/4K4 w - - 0 1",
+		},
+		{
+			name:   "central cannon",
+			better: "rnbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C2C4/9/RNBAKABNR b - - 1 1",
+			worse:  "rnbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C5C1/9/RNBAKABNR w - - 0 1",
+		},
+		{
+			name:   "rook on open file",
+			better: "3k5/9/9/9/p8/9/9/9/9/1R2K4 w - - 0 1",
+			worse:  "3k5/9/9/9/p8/9/9/9/9/R3K4 w - - 0 1",
+		},
+		{
+			name:   "mobile horse",
+			better: "3k5/9/9/9/9/4N4/9/9/9/4K4 w - - 0 1",
+			worse:  "3k5/9/9/9/9/9/9/9/9/N3K4 w - - 0 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			better, err := ParseFEN(tc.better)
+			if err != nil {
+				t.Fatalf("Failed to parse the FEN: %v", err)
+			}
+			worse, err := ParseFEN(tc.worse)
+			if err != nil {
+				t.Fatalf("Failed to parse the FEN: %v", err)
+			}
+			if b, w := positional.Evaluate(better, Red), positional.Evaluate(worse, Red); b <= w {
+				t.Errorf("Unexpected scores, want: %d > %d", b, w)
+			}
+		})
+	}
+}
+
+func TestEvaluateKingSafety(t *testing.T) {
+	// The guard is worth more when the opponent has a rook to attack the
+	// king.
+	guardValue := func(withGuard, withoutGuard string) int {
+		t.Helper()
+		b1, err := ParseFEN(withGuard)
+		if err != nil {
+			t.Fatalf("Failed to parse the FEN: %v", err)
+		}
+		b2, err := ParseFEN(withoutGuard)
+		if err != nil {
+			t.Fatalf("Failed to parse the FEN: %v", err)
+		}
+		return positional.Evaluate(b1, Red) - positional.Evaluate(b2, Red)
+	}
+
+	safe := guardValue("3k5/9/9/9/9/9/9/9/4A4/3AK4 w - - 0 1", "3k5/9/9/9/9/9/9/9/9/3AK4 w - - 0 1")
+	attacked := guardValue("3k5/9/9/9/9/9/9/9/4A4/r2AK4 w - - 0 1", "3k5/9/9/9/9/9/9/9/9/r2AK4 w - - 0 1")
+	if attacked <= safe {
+		t.Errorf("Unexpected value of the guard under attack, want: %d > %d", attacked, safe)
+	}
+}
@@ -0,0 +1,45 @@
+package rules
+
+// The score of a soldier at home, which is the unit of the evaluation, so
+// the material scores are about pieceValueMap * soldierValue.
+const soldierValue = 100
+
+// Evaluator evaluates the positions at the leaves of the search, so the
+// evaluation functions can be experimented without changing the search.
+//
+// The scores are in the unit of soldierValue (a soldier at home is 100),
+// which the margins of the search, e.g. of the delta pruning, are based on.
+// An evaluator must be safe for concurrent use, since it's shared by the
+// threads of the search.
+type Evaluator interface {
+	// Evaluate returns the static score of the board from the perspective
+	// of the color, no matter which side is to move.
+	Evaluate(b *Board, color PieceColor) int
+}
+
+// Evaluators are the built-in evaluators by name, which can be selected
+// from the command line.
+var Evaluators = map[string]Evaluator{
+	"material":   MaterialEvaluator{},
+	"positional": PositionalEvaluator{},
+}
+
+// MaterialEvaluator only counts the material by pieceValueMap.
+type MaterialEvaluator struct{}
+
+func (MaterialEvaluator) Evaluate(b *Board, color PieceColor) int {
+	score := 0
+	// iterate the piece lists instead of the whole board
+	for slot := redTag; slot < blackTag+16; slot++ {
+		if b.pieces[slot] == 0 {
+			continue
+		}
+		if slotColor(slot) == color {
+			score += pieceValues[slotRole(slot)]
+		} else {
+			score -= pieceValues[slotRole(slot)]
+		}
+	}
+
+	return score * soldierValue
+}
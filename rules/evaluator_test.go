@@ -0,0 +1,44 @@
+package rules
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMaterialEvaluator(t *testing.T) {
+	var material MaterialEvaluator
+	if got := material.Evaluate(NewBoard(), Red); got != 0 {
+		t.Errorf("Unexpected score of the start position, want: 0, got: %d", got)
+	}
+
+	// Black is short of a rook.
+	b, err := ParseFEN("1nbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C5C1/9/RNBAKABNR w - - 0 1")
+	if err != nil {
+		t.Fatalf("Failed to parse the FEN: %v", err)
+	}
+	want := pieceValueMap[RoleRook] * soldierValue
+	if got := material.Evaluate(b, Red); got != want {
+		t.Errorf("Unexpected score of Red, want: %d, got: %d", want, got)
+	}
+	if got := material.Evaluate(b, Black); got != -want {
+		t.Errorf("Unexpected score of Black, want: %d, got: %d", -want, got)
+	}
+}
+
+func TestEvaluators(t *testing.T) {
+	// The red rook captures the unprotected black rook.
+	b, err := ParseFEN("4k4/9/9/9/4r4/9/9/9/4R4/3K5 w - - 0 1")
+	if err != nil {
+		t.Fatalf("Failed to parse the FEN: %v", err)
+	}
+	for name, evaluator := range Evaluators {
+		t.Run(name, func(t *testing.T) {
+			options := DefaultEngineOptions
+			options.Evaluator = evaluator
+			result := NewEngine(options).Search(context.Background(), b, SearchLimits{Depth: 3})
+			if got := result.Move.ICCS(); got != "e1e5" {
+				t.Errorf("Unexpected best move, want: e1e5, got: %s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StartFEN is the FEN of the initial position.
+const StartFEN = "rnbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C5C1/9/RNBAKABNR w - - 0 1"
+
+// fenLetterMap maps the role to the FEN letter of the black piece. The
+// letter of the red piece is the upper case.
+var fenLetterMap = map[PieceRole]byte{
+	RoleKing:   'k',
+	RoleGuard:  'a',
+	RoleBishop: 'b',
+	RoleHorse:  'n',
+	RoleRook:   'r',
+	RoleCannon: 'c',
+	RoleSolder: 'p',
+}
+
+// fenRoleMap maps the lower case FEN letter to the role. Some tools
+// use 'h' for the horse and 'e' for the bishop (elephant), so they
+// are accepted as well.
+var fenRoleMap = map[byte]PieceRole{
+	'k': RoleKing,
+	'a': RoleGuard,
+	'b': RoleBishop,
+	'e': RoleBishop,
+	'n': RoleHorse,
+	'h': RoleHorse,
+	'r': RoleRook,
+	'c': RoleCannon,
+	'p': RoleSolder,
+}
+
+// ParseFEN creates a board from a Xiangqi FEN string, e.g.
+// "rnbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C5C1/9/RNBAKABNR w - - 0 1".
+// The upper case letters are the Red pieces, and the first rank is the
+// Black's back rank. The side to move and the move counters are optional,
+// and default to "w", 0 and 1 respectively.
+func ParseFEN(fen string) (*Board, error) {
+	fields := strings.Fields(fen)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty FEN")
+	}
+
+	b := &Board{
+		position: position{
+			isRedTurn:      true,
+			fullMoveNumber: 1,
+		},
+		result:    Ongoing,
+		ruleSet:   RuleSetAXF,
+		drawRules: DefaultDrawRules,
+	}
+
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 10 {
+		return nil, fmt.Errorf("invalid FEN %q: expected 10 ranks, got %d", fen, len(ranks))
+	}
+	for i, rank := range ranks {
+		j := 0
+		for k := 0; k < len(rank); k++ {
+			c := rank[k]
+			if c >= '1' && c <= '9' {
+				j += int(c - '0')
+				if j > 9 {
+					return nil, fmt.Errorf("invalid FEN %q: too many files in rank %d", fen, i)
+				}
+				continue
+			}
+			color := Black
+			if c >= 'A' && c <= 'Z' {
+				color = Red
+				c += 'a' - 'A'
+			}
+			role, ok := fenRoleMap[c]
+			if !ok {
+				return nil, fmt.Errorf("invalid FEN %q: unknown piece %q", fen, rank[k])
+			}
+			if j > 8 {
+				return nil, fmt.Errorf("invalid FEN %q: too many files in rank %d", fen, i)
+			}
+			if err := b.addPiece(toSquare(i, j), Piece{color, role}); err != nil {
+				return nil, fmt.Errorf("invalid FEN %q: %w", fen, err)
+			}
+			j++
+		}
+		if j != 9 {
+			return nil, fmt.Errorf("invalid FEN %q: expected 9 files in rank %d, got %d", fen, i, j)
+		}
+	}
+
+	if len(fields) > 1 {
+		switch fields[1] {
+		case "w", "r":
+			b.isRedTurn = true
+		case "b":
+			b.isRedTurn = false
+		default:
+			return nil, fmt.Errorf("invalid FEN %q: unknown side to move %q", fen, fields[1])
+		}
+	}
+
+	// fields[2] and fields[3] are castling and en passant in the chess FEN,
+	// which are always "-" in Xiangqi.
+	if len(fields) > 4 {
+		n, err := strconv.Atoi(fields[4])
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid FEN %q: invalid half move clock %q", fen, fields[4])
+		}
+		b.halfMoveClock = n
+	}
+	if len(fields) > 5 {
+		n, err := strconv.Atoi(fields[5])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid FEN %q: invalid full move number %q", fen, fields[5])
+		}
+		b.fullMoveNumber = n
+	}
+
+	if err := b.validateKings(); err != nil {
+		return nil, fmt.Errorf("invalid FEN %q: %w", fen, err)
+	}
+	if !b.isRedTurn {
+		b.hash ^= zobristBlackKey
+	}
+	b.history = []historyEntry{{hash: b.hash}}
+	b.updateResult()
+
+	return b, nil
+}
+
+// FEN returns the Xiangqi FEN string of the board.
+func (b *Board) FEN() string {
+	var sb strings.Builder
+	for i := 0; i <= 9; i++ {
+		if i > 0 {
+			sb.WriteByte('/')
+		}
+		empty := 0
+		for j := 0; j <= 8; j++ {
+			p, ok := b.pieceAt(i, j)
+			if !ok {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				sb.WriteByte(byte('0' + empty))
+				empty = 0
+			}
+			c := fenLetterMap[p.role]
+			if p.color == Red {
+				c -= 'a' - 'A'
+			}
+			sb.WriteByte(c)
+		}
+		if empty > 0 {
+			sb.WriteByte(byte('0' + empty))
+		}
+	}
+
+	side := "w"
+	if !b.isRedTurn {
+		side = "b"
+	}
+	fmt.Fprintf(&sb, " %s - - %d %d", side, b.halfMoveClock, b.fullMoveNumber)
+
+	return sb.String()
+}
+
+// validateKings checks that each side has a king, and it's inside its
+// own palace. Note the engine doesn't allow more than one king per side.
+// The king of the side not to move mustn't be in check (including facing
+// the other king), otherwise capturing the king would be a legal move.
+func (b *Board) validateKings() error {
+	for _, color := range []PieceColor{Red, Black} {
+		sq := b.kingSquare(color)
+		if sq == 0 {
+			return fmt.Errorf("the %s king is missing", color)
+		}
+		if !isInPalace(rowOf(sq), colOf(sq), color) {
+			return fmt.Errorf("the %s king is out of the palace", color)
+		}
+	}
+	if waiting := opponent(b.color()); b.isInCheck(waiting) {
+		return fmt.Errorf("the %s king is in check, but it's %s's turn", waiting, b.color())
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFEN(t *testing.T) {
+	for _, fen := range []string{
+		StartFEN,
+		"rnbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C2C4/9/RNBAKABNR b - - 1 1",
+		"3k5/9/9/9/9/9/9/9/9/4K4 w - - 12 34",
+	} {
+		b, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", fen, err)
+		}
+		if got := b.FEN(); got != fen {
+			t.Errorf("Unexpected FEN, want: %s, got: %s", fen, got)
+		}
+	}
+
+	// The side to move and the move counters are optional.
+	b, err := ParseFEN("rnbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C5C1/9/RNBAKABNR")
+	if err != nil {
+		t.Fatalf("Failed to parse the FEN without the optional fields: %v", err)
+	}
+	if got := b.FEN(); got != StartFEN {
+		t.Errorf("Unexpected FEN, want: %s, got: %s", StartFEN, got)
+	}
+}
+
+func TestParseFENError(t *testing.T) {
+	testCases := []struct {
+		name string
+		fen  string
+		want string
+	}{
+		{name: "empty", fen: "", want: "empty FEN"},
+		{name: "too few ranks", fen: "3k5/9/9/9/9/9/9/9/4K4 w - - 0 1", want: "expected 10 ranks"},
+		{name: "too many files", fen: "3k6/9/9/9/9/9/9/9/9/4K4 w - - 0 1", want: "too many files"},
+		{name: "piece beyond the files", fen: "3k4r1/9/9/9/9/9/9/9/9/4K4 w - - 0 1", want: "too many files"},
+		{name: "too few files", fen: "3k4/9/9/9/9/9/9/9/9/4K4 w - - 0 1", want: "expected 9 files"},
+		{name: "unknown piece", fen: "3k5/9/9/9/4x4/9/9/9/9/4K4 w - - 0 1", want: "unknown piece"},
+		{name: "too many pieces", fen: "3k5/9/9/9/9/9/9/9/RRR6/4K4 w - - 0 1", want: "too many red rooks"},
+		{name: "unknown side", fen: "3k5/9/9/9/9/9/9/9/9/4K4 x - - 0 1", want: "unknown side to move"},
+		{name: "invalid half move clock", fen: "3k5/9/9/9/9/9/9/9/9/4K4 w - - -1 1", want: "invalid half move clock"},
+		{name: "invalid full move number", fen: "3k5/9/9/9/9/9/9/9/9/4K4 w - - 0 0", want: "invalid full move number"},
+		{name: "missing king", fen: "3k5/9/9/9/9/9/9/9/9/4A4 w - - 0 1", want: "the red king is missing"},
+		{name: "king out of palace", fen: "k8/9/9/9/9/9/9/9/9/4K4 w - - 0 1", want: "the black king is out of the palace"},
+		{name: "opponent in check", fen: "4k4/9/9/9/9/9/9/9/4R4/3K5 w - - 0 1", want: "the black king is in check"},
+		{name: "facing kings", fen: "4k4/9/9/9/9/9/9/9/9/4K4 b - - 0 1", want: "the red king is in check"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseFEN(tc.fen)
+			if err == nil {
+				t.Fatalf("Expected an error parsing %q", tc.fen)
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Unexpected error, want: %q, got: %v", tc.want, err)
+			}
+		})
+	}
+
+	// The side to move may be in check.
+	if _, err := ParseFEN("4k4/9/9/9/9/9/9/9/4R4/3K5 b - - 0 1"); err != nil {
+		t.Errorf("Failed to parse the position in check: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package rules
+
+// The occupancy of each row and column is kept as a bit set (位行/位列),
+// which is updated incrementally by makeMove and unmakeMove at the cost of
+// a few XORs. The attacks along a line are looked up in the tables below
+// by the position on the line and the occupancy of the line, instead of
+// scanning the squares one by one.
+
+// lineAttacks is what a rook and a cannon at a position of a line can
+// capture, given the occupancy of the line. Each field has two entries,
+// toward the lower and the higher positions, which are the positions of
+// the target pieces, or -1 if there isn't any.
+type lineAttacks struct {
+	// the first piece, which is captured by a rook (or the facing king)
+	rook [2]int8
+	// the second piece, which is captured by a cannon over the first one
+	cannon [2]int8
+}
+
+var (
+	// rowAttacks is indexed by the column and the occupancy of the row.
+	rowAttacks [9][1 << 9]lineAttacks
+	// colAttacks is indexed by the row and the occupancy of the column.
+	colAttacks [10][1 << 10]lineAttacks
+)
+
+func init() {
+	for pos := 0; pos < 9; pos++ {
+		for occ := range rowAttacks[pos] {
+			rowAttacks[pos][occ] = newLineAttacks(pos, 9, occ)
+		}
+	}
+	for pos := 0; pos < 10; pos++ {
+		for occ := range colAttacks[pos] {
+			colAttacks[pos][occ] = newLineAttacks(pos, 10, occ)
+		}
+	}
+}
+
+// newLineAttacks computes the attacks from the position of a line of
+// `length` squares, whose occupancy is `occ`.
+func newLineAttacks(pos, length, occ int) lineAttacks {
+	a := lineAttacks{rook: [2]int8{-1, -1}, cannon: [2]int8{-1, -1}}
+	for dir, step := range [2]int{-1, 1} {
+		found := 0
+		for i := pos + step; i >= 0 && i < length && found < 2; i += step {
+			if occ&(1<<i) == 0 {
+				continue
+			}
+			if found == 0 {
+				a.rook[dir] = int8(i)
+			} else {
+				a.cannon[dir] = int8(i)
+			}
+			found++
+		}
+	}
+	return a
+}
+
+// toggleOccupancy flips the bit of the square in the occupancy of its row
+// and column, when a piece is put on or taken off the square.
+func (p *position) toggleOccupancy(sq square) {
+	row, col := rowOf(sq), colOf(sq)
+	p.rowBits[row] ^= 1 << col
+	p.colBits[col] ^= 1 << row
+}
@@ -0,0 +1,101 @@
+package rules
+
+import "testing"
+
+// isInCheckByScan is the reference of isInCheck, which scans the squares
+// from the king one by one, including the horses and the soldiers on all
+// the squares attacking the king.
+func (p *position) isInCheckByScan(color PieceColor) bool {
+	king := p.kingSquare(color)
+	isOpponent := func(sq square, role int) bool {
+		slot := int(p.squares[sq])
+		return slot != 0 && slotColor(slot) != color && slotRole(slot) == role
+	}
+	for _, d := range orthogonalDeltas {
+		screens := 0
+		for sq := king + d; inBoard[sq]; sq += d {
+			if p.squares[sq] == 0 {
+				continue
+			}
+			if screens == 0 && (isOpponent(sq, rookIndex) || (d == stepUp || d == stepDown) && isOpponent(sq, kingIndex)) {
+				return true
+			}
+			if screens == 1 && isOpponent(sq, cannonIndex) {
+				return true
+			}
+			if screens++; screens > 1 {
+				break
+			}
+		}
+	}
+	for slot := sideTag(opponent(color)); slot < sideTag(opponent(color))+16; slot++ {
+		from := square(p.pieces[slot])
+		if from == 0 || (slotRole(slot) != horseIndex && slotRole(slot) != soldierIndex) {
+			continue
+		}
+		for _, mv := range p.generatePieceMoves(slot, from, nil) {
+			if mv.to() == king {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkOccupancy checks the occupancy of the rows and the columns against
+// the squares.
+func checkOccupancy(t *testing.T, p *position) {
+	t.Helper()
+	var rowBits [10]uint16
+	var colBits [9]uint16
+	for i := 0; i <= 9; i++ {
+		for j := 0; j <= 8; j++ {
+			if p.squares[toSquare(i, j)] != 0 {
+				rowBits[i] |= 1 << j
+				colBits[j] |= 1 << i
+			}
+		}
+	}
+	if rowBits != p.rowBits || colBits != p.colBits {
+		t.Fatalf("Unexpected occupancy, want: %v %v, got: %v %v", rowBits, colBits, p.rowBits, p.colBits)
+	}
+}
+
+func TestIncrementalAttacks(t *testing.T) {
+	for _, tc := range perftCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := ParseFEN(tc.fen)
+			if err != nil {
+				t.Fatalf("Failed to parse the FEN: %v", err)
+			}
+			checkOccupancy(t, &b.position)
+
+			// Walk through the pseudo-legal moves to depth 2, where the kings
+			// may be in check, or even captured.
+			var walk func(depth int)
+			walk = func(depth int) {
+				for _, color := range []PieceColor{Red, Black} {
+					if b.kingSquare(color) == 0 {
+						return
+					}
+				}
+				for _, color := range []PieceColor{Red, Black} {
+					if got, want := b.isInCheck(color), b.isInCheckByScan(color); got != want {
+						t.Fatalf("Unexpected check of the %s king in %s, want: %t, got: %t", color, b.FEN(), want, got)
+					}
+				}
+				if depth == 0 {
+					return
+				}
+				for _, mv := range b.generateMoves(nil) {
+					b.makeMove(mv)
+					checkOccupancy(t, &b.position)
+					walk(depth - 1)
+					b.unmakeMove()
+					checkOccupancy(t, &b.position)
+				}
+			}
+			walk(2)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package rules
+
+import (
+	"fmt"
+	"image"
+)
+
+// Move is a move of a piece from one point to another.
+// Note the point.X is the row number (0-9), and point.Y is the column number (0-8).
+type Move struct {
+	From image.Point
+	To   image.Point
+	// The moving piece
+	Piece Piece
+	// The captured piece, or the zero Piece if it isn't a capture. It's
+	// stored by value, so the moves can be compared by ==.
+	Captured Piece
+}
+
+// IsCapture returns true if the move captures an opponent's piece.
+func (m Move) IsCapture() bool {
+	return m.Captured != (Piece{})
+}
+
+func (m Move) String() string {
+	return fmt.Sprintf("%s, %s --> %s", m.Piece.String(), m.From.String(), m.To.String())
+}
+
+// isValidPoint checks whether the point is on the board.
+func isValidPoint(pt image.Point) bool {
+	return pt.X >= 0 && pt.X <= 9 && pt.Y >= 0 && pt.Y <= 8
+}
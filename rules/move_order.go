@@ -0,0 +1,88 @@
+package rules
+
+// The alpha-beta search prunes the most when the best move is searched
+// first, so the moves are searched in the order of:
+//  1. the best move stored in the transposition table;
+//  2. the captures, the most valuable victim first, and then the least
+//     valuable attacker first (MVV-LVA);
+//  3. the killer moves, which are the quiet moves causing a beta cutoff
+//     at the same ply in the sibling nodes;
+//  4. the other quiet moves, ordered by the history heuristic, which
+//     counts how often a move of the piece to the square causes a beta
+//     cutoff anywhere in the tree.
+const (
+	orderTTMove  = 1 << 30
+	orderCapture = 1 << 20
+	orderKiller  = 1 << 19
+)
+
+// The number of the killer moves kept per ply.
+const killersPerPly = 2
+
+// moveOrder keeps the killer moves and the history heuristic of a search.
+type moveOrder struct {
+	killers [maxSearchDepth + 1][killersPerPly]move
+	// indexed by the piece (see pieceIndex) and the destination square
+	history [14][256]int
+	// The buffer of the order scores of the moves being sorted, which is
+	// reused by all the nodes, since the scores aren't needed once the
+	// moves are sorted.
+	scores []int
+}
+
+// sort orders the moves in place at the ply, and ttMove (if not 0) is
+// searched first.
+func (o *moveOrder) sort(p *position, moves []move, ply int, ttMove move) {
+	scores := o.scores[:0]
+	for _, mv := range moves {
+		scores = append(scores, o.score(p, mv, ply, ttMove))
+	}
+	o.scores = scores
+
+	// The insertion sort is stable, which keeps the moves of the same score
+	// in the order of generation, so the search is deterministic. It sorts
+	// the moves and the scores together without allocation, and it's fast
+	// for the few dozens of moves of a position.
+	for i := 1; i < len(moves); i++ {
+		mv, score := moves[i], scores[i]
+		j := i
+		for ; j > 0 && scores[j-1] < score; j-- {
+			moves[j], scores[j] = moves[j-1], scores[j-1]
+		}
+		moves[j], scores[j] = mv, score
+	}
+}
+
+func (o *moveOrder) score(p *position, mv move, ply int, ttMove move) int {
+	if mv == ttMove {
+		return orderTTMove
+	}
+	attacker := int(p.squares[mv.from()])
+	if victim := int(p.squares[mv.to()]); victim != 0 {
+		return orderCapture + pieceValues[slotRole(victim)]*256 - pieceValues[slotRole(attacker)]
+	}
+	if ply <= maxSearchDepth {
+		for i, killer := range o.killers[ply] {
+			if mv == killer {
+				return orderKiller - i
+			}
+		}
+	}
+	// The history score never exceeds the killers.
+	return min(o.history[pieceIndex(attacker)][mv.to()], orderKiller-killersPerPly)
+}
+
+// cutoff records the move which has caused a beta cutoff at the ply, with
+// the remaining depth. Only the quiet moves are recorded, since the captures
+// are ordered anyway.
+func (o *moveOrder) cutoff(p *position, mv move, ply, depth int) {
+	if p.squares[mv.to()] != 0 {
+		return
+	}
+	if ply <= maxSearchDepth && o.killers[ply][0] != mv {
+		copy(o.killers[ply][1:], o.killers[ply][:killersPerPly-1])
+		o.killers[ply][0] = mv
+	}
+	// The deeper the subtree is, the more the cutoff saves.
+	o.history[pieceIndex(int(p.squares[mv.from()]))][mv.to()] += depth * depth
+}
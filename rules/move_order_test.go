@@ -0,0 +1,62 @@
+package rules
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMoveOrder(t *testing.T) {
+	// The red rook can capture the black rook or the black soldier, and the
+	// red cannon can capture the black rook over the soldier.
+	b, err := ParseFEN("3k5/9/9/4r4/9/4p4/9/4C4/R3p4/5K3 w - - 0 1")
+	if err != nil {
+		t.Fatalf("Failed to parse the FEN: %v", err)
+	}
+	iccs := func(mv move) string {
+		return b.toMove(mv).ICCS()
+	}
+	find := func(s string) move {
+		m, err := b.ParseICCS(s)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", s, err)
+		}
+		return newMove(toSquare(m.From.X, m.From.Y), toSquare(m.To.X, m.To.Y))
+	}
+
+	var o moveOrder
+	killer := find("a1a5")
+	o.cutoff(&b.position, killer, 2, 3)
+
+	moves := b.legalMoves(nil)
+	ttMove := find("a1a2")
+	o.sort(&b.position, moves, 2, ttMove)
+
+	want := []string{
+		"a1a2", // the TT move
+		"e2e6", // the cannon captures the rook
+		"a1e1", // the rook captures the soldier
+		"a1a5", // the killer
+	}
+	for i, s := range want {
+		if got := iccs(moves[i]); got != s {
+			t.Errorf("Unexpected move #%d, want: %s, got: %s", i, s, got)
+		}
+	}
+}
+
+func TestMoveOrderPruning(t *testing.T) {
+	b := NewBoard()
+	search := func(ordering bool) SearchResult {
+		engine := NewEngine(EngineOptions{HashSize: 1, DeltaPruning: true, MoveOrdering: ordering})
+		return engine.Search(context.Background(), b, SearchLimits{Depth: 4})
+	}
+
+	unordered, ordered := search(false), search(true)
+	if ordered.Score != unordered.Score {
+		t.Errorf("Unexpected score with the move ordering, want: %d, got: %d", unordered.Score, ordered.Score)
+	}
+	if ordered.Nodes*2 > unordered.Nodes {
+		t.Errorf("Expected the move ordering to save at least half of the nodes, without: %d, with: %d", unordered.Nodes, ordered.Nodes)
+	}
+	t.Logf("Nodes without the move ordering: %d, with: %d", unordered.Nodes, ordered.Nodes)
+}
@@ -0,0 +1,267 @@
+package rules
+
+var (
+	// up, down, left, right
+	orthogonalDeltas = [4]int{stepUp, stepDown, stepLeft, stepRight}
+	diagonalDeltas   = [4]int{stepUp + stepLeft, stepUp + stepRight, stepDown + stepLeft, stepDown + stepRight}
+
+	// The eight targets of the horse, each with the leg which must be empty.
+	horseDeltas = [8]struct{ target, leg int }{
+		{2*stepUp + stepLeft, stepUp},
+		{2*stepUp + stepRight, stepUp},
+		{2*stepDown + stepLeft, stepDown},
+		{2*stepDown + stepRight, stepDown},
+		{2*stepLeft + stepUp, stepLeft},
+		{2*stepLeft + stepDown, stepLeft},
+		{2*stepRight + stepUp, stepRight},
+		{2*stepRight + stepDown, stepRight},
+	}
+
+	// inPalace tells whether the square is inside the 3*3 grid (九宫格)
+	// of the color: [0] for Red and [1] for Black.
+	inPalace [2][256]bool
+	// ownSide tells whether the square is inside the country of the color:
+	// [0] for Red and [1] for Black.
+	ownSide [2][256]bool
+)
+
+func init() {
+	for i := 0; i <= 9; i++ {
+		for j := 0; j <= 8; j++ {
+			sq := toSquare(i, j)
+			inPalace[0][sq] = isInPalace(i, j, Red)
+			inPalace[1][sq] = isInPalace(i, j, Black)
+			ownSide[0][sq] = isOwnSide(i, Red)
+			ownSide[1][sq] = isOwnSide(i, Black)
+		}
+	}
+}
+
+// colorIndex returns 0 for Red and 1 for Black.
+func colorIndex(color PieceColor) int {
+	if color == Red {
+		return 0
+	}
+	return 1
+}
+
+// forwardDelta returns the delta of the squares of one step forward.
+func forwardDelta(color PieceColor) int {
+	if color == Red {
+		return stepUp
+	}
+	return stepDown
+}
+
+// generateMoves appends the pseudo-legal moves of the side to move, and
+// returns the extended slice. The moves follow the rules of each role,
+// but the own king might be in danger after the move.
+func (p *position) generateMoves(moves []move) []move {
+	color := p.color()
+	tag := sideTag(color)
+	for slot := tag; slot < tag+16; slot++ {
+		from := square(p.pieces[slot])
+		if from == 0 {
+			continue
+		}
+		moves = p.generatePieceMoves(slot, from, moves)
+	}
+	return moves
+}
+
+// generatePieceMoves appends the pseudo-legal moves of the piece of the slot on `from`.
+func (p *position) generatePieceMoves(slot int, from square, moves []move) []move {
+	color := slotColor(slot)
+	tag, ci := sideTag(color), colorIndex(color)
+	// canLand checks whether the piece can land on the square, which must
+	// be either empty or occupied by an opponent's piece.
+	canLand := func(sq square) bool {
+		return int(p.squares[sq])&tag == 0
+	}
+
+	switch slotRole(slot) {
+	case rookIndex:
+		// Slide along the four rays until the first piece, which can be
+		// captured if it's an opponent's piece.
+		for _, d := range orthogonalDeltas {
+			to := from + d
+			for ; inBoard[to] && p.squares[to] == 0; to += d {
+				moves = append(moves, newMove(from, to))
+			}
+			if inBoard[to] && canLand(to) {
+				moves = append(moves, newMove(from, to))
+			}
+		}
+	case cannonIndex:
+		// Slide along the four rays until the first piece (the platform),
+		// and then capture the next piece beyond the platform if it's an
+		// opponent's piece.
+		for _, d := range orthogonalDeltas {
+			to := from + d
+			for ; inBoard[to] && p.squares[to] == 0; to += d {
+				moves = append(moves, newMove(from, to))
+			}
+			if !inBoard[to] {
+				continue
+			}
+			for to += d; inBoard[to]; to += d {
+				if p.squares[to] == 0 {
+					continue
+				}
+				if canLand(to) {
+					moves = append(moves, newMove(from, to))
+				}
+				break
+			}
+		}
+	case horseIndex:
+		// Jump in the 'L' shape (or 日) unless the leg is blocked.
+		for _, d := range horseDeltas {
+			to := from + d.target
+			if inBoard[to] && canLand(to) && p.squares[from+d.leg] == 0 {
+				moves = append(moves, newMove(from, to))
+			}
+		}
+	case bishopIndex:
+		// Move two steps diagonally inside its own country unless the eye is blocked.
+		for _, d := range diagonalDeltas {
+			to := from + d*2
+			if ownSide[ci][to] && canLand(to) && p.squares[from+d] == 0 {
+				moves = append(moves, newMove(from, to))
+			}
+		}
+	case guardIndex:
+		// Move one step diagonally inside its own palace.
+		for _, d := range diagonalDeltas {
+			to := from + d
+			if inPalace[ci][to] && canLand(to) {
+				moves = append(moves, newMove(from, to))
+			}
+		}
+	case kingIndex:
+		// Move one step horizontally or vertically inside its own palace.
+		for _, d := range orthogonalDeltas {
+			to := from + d
+			if inPalace[ci][to] && canLand(to) {
+				moves = append(moves, newMove(from, to))
+			}
+		}
+	case soldierIndex:
+		// Move one step forward, or sideways once it has crossed the river.
+		if to := from + forwardDelta(color); inBoard[to] && canLand(to) {
+			moves = append(moves, newMove(from, to))
+		}
+		if !ownSide[ci][from] {
+			for _, to := range [2]square{from + stepLeft, from + stepRight} {
+				if inBoard[to] && canLand(to) {
+					moves = append(moves, newMove(from, to))
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// isInCheck checks whether the king of the specified color is in danger,
+// including the case that the two kings are facing each other. Instead of
+// scanning the whole board, it looks for the attackers from the king square:
+// the rooks, the cannons and the facing king are looked up by the occupancy
+// of the row and the column of the king (see lineAttacks), which is kept up
+// to date by makeMove and unmakeMove, and the horses and the soldiers are
+// on a few fixed squares around the king.
+func (p *position) isInCheck(color PieceColor) bool {
+	opponent := Black
+	if color == Black {
+		opponent = Red
+	}
+	king := p.kingSquare(color)
+	oppTag := sideTag(opponent)
+	isOpponent := func(sq square, role int) bool {
+		slot := int(p.squares[sq])
+		return slot&oppTag != 0 && slotRole(slot) == role
+	}
+
+	row, col := rowOf(king), colOf(king)
+	rowLine := &rowAttacks[col][p.rowBits[row]]
+	colLine := &colAttacks[row][p.colBits[col]]
+	for dir := 0; dir < 2; dir++ {
+		if i := rowLine.rook[dir]; i >= 0 && isOpponent(toSquare(row, int(i)), rookIndex) {
+			return true
+		}
+		if i := rowLine.cannon[dir]; i >= 0 && isOpponent(toSquare(row, int(i)), cannonIndex) {
+			return true
+		}
+		if i := colLine.rook[dir]; i >= 0 {
+			if sq := toSquare(int(i), col); isOpponent(sq, rookIndex) || isOpponent(sq, kingIndex) {
+				return true
+			}
+		}
+		if i := colLine.cannon[dir]; i >= 0 && isOpponent(toSquare(int(i), col), cannonIndex) {
+			return true
+		}
+	}
+
+	// The horse on `king - target` attacks the king unless its leg is blocked.
+	for _, d := range horseDeltas {
+		sq := king - d.target
+		if inBoard[sq] && isOpponent(sq, horseIndex) && p.squares[sq+d.leg] == 0 {
+			return true
+		}
+	}
+
+	// The soldier attacks forward, and sideways. Note an opponent's soldier
+	// which is next to the king must have crossed the river.
+	for _, sq := range [3]square{king - forwardDelta(opponent), king + stepLeft, king + stepRight} {
+		if isOpponent(sq, soldierIndex) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// legalMoves appends the legal moves of the side to move, and returns the
+// extended slice. The pseudo-legal moves are filtered by making each of
+// them, and checking whether the own king is in danger.
+func (p *position) legalMoves(moves []move) []move {
+	start := len(moves)
+	moves = p.generateMoves(moves)
+
+	legal := moves[:start]
+	for _, mv := range moves[start:] {
+		if p.isLegal(mv) {
+			legal = append(legal, mv)
+		}
+	}
+	return legal
+}
+
+// legalCaptures is the same as legalMoves, but only the captures are
+// appended.
+func (p *position) legalCaptures(moves []move) []move {
+	start := len(moves)
+	moves = p.generateMoves(moves)
+
+	legal := moves[:start]
+	for _, mv := range moves[start:] {
+		if p.squares[mv.to()] != 0 && p.isLegal(mv) {
+			legal = append(legal, mv)
+		}
+	}
+	return legal
+}
+
+// hasLegalMoves checks whether the side to move has any legal move.
+func (p *position) hasLegalMoves() bool {
+	for _, mv := range p.generateMoves(nil) {
+		if p.isLegal(mv) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLegal checks whether the own king is safe after the pseudo-legal move.
+func (p *position) isLegal(mv move) bool {
+	return p.isSafeFor(mv, p.color())
+}
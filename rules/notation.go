@@ -0,0 +1,220 @@
+package rules
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/ahrtr/chess/utils"
+)
+
+// The notations are all relative to the mover. The files are numbered
+// 1~9 from the right to the left of the mover, so the Red's file 1 is
+// the column 8, and the Black's file 1 is the column 0.
+
+// moveNotation is the notation independent description of a move in
+// the WXF or Chinese notation.
+type moveNotation struct {
+	role PieceRole
+	// The index (from the front, 0 based) of the moving piece among the
+	// pieces of the same role on the same file; it's -1 if the piece isn't
+	// a tandem piece.
+	tandemIndex int
+	// The number of the tandem pieces on the file of the moving piece.
+	tandemCount int
+	// Whether there are soldiers of the mover in tandem on more than one
+	// file, in which case the file number is also needed.
+	multiFile bool
+	// The file (1~9) of the moving piece.
+	file int
+	// 1: forward; -1: backward; 0: sideways.
+	direction int
+	// The number of steps for the rook, cannon, king and soldier moving
+	// forward or backward; otherwise the destination file (1~9).
+	num int
+}
+
+// fileOf returns the file number (1~9) of the column from the perspective
+// of the specified color.
+func fileOf(col int, color PieceColor) int {
+	if color == Red {
+		return 9 - col
+	}
+	return col + 1
+}
+
+// describeMove describes the move in the position before the move is made.
+func (b *Board) describeMove(m Move) moveNotation {
+	p := m.Piece
+	n := moveNotation{
+		role:        p.role,
+		tandemIndex: -1,
+		file:        fileOf(m.From.Y, p.color),
+	}
+
+	dx := (m.To.X - m.From.X) * forward(p.color)
+	switch {
+	case dx > 0:
+		n.direction = 1
+	case dx < 0:
+		n.direction = -1
+	}
+
+	straight := p.role == RoleRook || p.role == RoleCannon || p.role == RoleKing || p.role == RoleSolder
+	if straight && n.direction != 0 {
+		n.num = utils.Abs(dx)
+	} else {
+		n.num = fileOf(m.To.Y, p.color)
+	}
+
+	// The guards and bishops don't need to be disambiguated, because
+	// the direction is always enough to tell them apart.
+	if p.role == RoleGuard || p.role == RoleBishop || p.role == RoleKing {
+		return n
+	}
+
+	tandem := b.tandemRows(m.From.Y, p)
+	if len(tandem) < 2 {
+		return n
+	}
+	n.tandemCount = len(tandem)
+	for i, x := range tandem {
+		if x == m.From.X {
+			n.tandemIndex = i
+		}
+	}
+
+	if p.role == RoleSolder {
+		files := 0
+		for j := 0; j <= 8; j++ {
+			if len(b.tandemRows(j, p)) >= 2 {
+				files++
+			}
+		}
+		n.multiFile = files >= 2
+	}
+
+	return n
+}
+
+// tandemRows returns the rows of the pieces same as `p` on the column,
+// ordered from the front to the rear from the perspective of the piece.
+func (b *Board) tandemRows(col int, p Piece) []int {
+	var rows []int
+	for i := 0; i <= 9; i++ {
+		x := i
+		if p.color == Black {
+			x = 9 - i
+		}
+		if q, ok := b.pieceAt(x, col); ok && q == p {
+			rows = append(rows, x)
+		}
+	}
+	return rows
+}
+
+// ICCS returns the move in the ICCS coordinate notation, e.g. "h2e2".
+// The files are a~i from the Red's left to right, and the ranks are
+// 0~9 from the Red's bottom to top.
+func (m Move) ICCS() string {
+	return iccsPoint(m.From) + iccsPoint(m.To)
+}
+
+func iccsPoint(pt image.Point) string {
+	return fmt.Sprintf("%c%d", 'a'+pt.Y, 9-pt.X)
+}
+
+// ParseICCS parses a move in the ICCS coordinate notation, e.g. "h2e2"
+// or "H2-E2", and returns the legal move in the current position.
+func (b *Board) ParseICCS(s string) (Move, error) {
+	str := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), "-", ""))
+	if len(str) != 4 {
+		return Move{}, fmt.Errorf("invalid ICCS move %q", s)
+	}
+
+	var pts [2]image.Point
+	for i := range pts {
+		file, rank := str[i*2], str[i*2+1]
+		if file < 'a' || file > 'i' || rank < '0' || rank > '9' {
+			return Move{}, fmt.Errorf("invalid ICCS move %q", s)
+		}
+		pts[i] = image.Point{X: 9 - int(rank-'0'), Y: int(file - 'a')}
+	}
+
+	m, ok := b.FindMove(pts[0], pts[1])
+	if !ok {
+		return Move{}, fmt.Errorf("illegal move %q", s)
+	}
+	return m, nil
+}
+
+// wxfLetterMap maps the role to the letter in the WXF notation.
+var wxfLetterMap = map[PieceRole]string{
+	RoleKing:   "K",
+	RoleGuard:  "A",
+	RoleBishop: "E",
+	RoleHorse:  "H",
+	RoleRook:   "R",
+	RoleCannon: "C",
+	RoleSolder: "P",
+}
+
+// WXF returns the move in the WXF notation, e.g. "C2=5" or "H8+7".
+// The move must be a legal move in the current position.
+//
+// Tandem pieces on the same file are denoted by "+" (front) and "-" (rear)
+// in place of the file number, e.g. "+C=5". When there are three or more
+// soldiers on the file, they are numbered from the front instead, e.g.
+// "2P=4". When soldiers are in tandem on more than one file, the letter
+// is replaced by the file number, e.g. "+7+1".
+func (b *Board) WXF(m Move) string {
+	n := b.describeMove(m)
+
+	var sb strings.Builder
+	if n.tandemIndex < 0 {
+		fmt.Fprintf(&sb, "%s%d", wxfLetterMap[n.role], n.file)
+	} else {
+		sb.WriteString(wxfTandemMarker(n.tandemIndex, n.tandemCount))
+		if n.multiFile {
+			fmt.Fprintf(&sb, "%d", n.file)
+		} else {
+			sb.WriteString(wxfLetterMap[n.role])
+		}
+	}
+
+	switch n.direction {
+	case 1:
+		sb.WriteByte('+')
+	case -1:
+		sb.WriteByte('-')
+	default:
+		sb.WriteByte('=')
+	}
+	fmt.Fprintf(&sb, "%d", n.num)
+
+	return sb.String()
+}
+
+func wxfTandemMarker(index, count int) string {
+	if count == 2 {
+		if index == 0 {
+			return "+"
+		}
+		return "-"
+	}
+	return fmt.Sprintf("%d", index+1)
+}
+
+// ParseWXF parses a move in the WXF notation, and returns the legal move
+// in the current position. It accepts "." for the sideways move, and the
+// letters "N" and "B" for the horse and bishop as well.
+func (b *Board) ParseWXF(s string) (Move, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	str = strings.NewReplacer(".", "=", "N", "H", "B", "E").Replace(str)
+	for _, m := range b.LegalMoves() {
+		if b.WXF(m) == str {
+			return m, nil
+		}
+	}
+	return Move{}, fmt.Errorf("invalid or illegal WXF move %q", s)
+}
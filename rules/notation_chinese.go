@@ -0,0 +1,127 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// chineseNameMap maps the piece to its name in the Chinese notation.
+// The traditional characters are used, same as the piece images, because
+// the embedded font doesn't have all the simplified ones (e.g. "进").
+var chineseNameMap = map[Piece]string{
+	{Red, RoleKing}:   "帥",
+	{Red, RoleGuard}:  "仕",
+	{Red, RoleBishop}: "相",
+	{Red, RoleHorse}:  "馬",
+	{Red, RoleRook}:   "車",
+	{Red, RoleCannon}: "炮",
+	{Red, RoleSolder}: "兵",
+
+	{Black, RoleKing}:   "将",
+	{Black, RoleGuard}:  "士",
+	{Black, RoleBishop}: "象",
+	{Black, RoleHorse}:  "馬",
+	{Black, RoleRook}:   "車",
+	{Black, RoleCannon}: "炮",
+	{Black, RoleSolder}: "卒",
+}
+
+var (
+	// The Red uses the Chinese numerals, and the Black uses the Arabic numerals.
+	redNumerals   = []string{"", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+	blackNumerals = []string{"", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
+)
+
+// chineseNormalizer converts the variants of the characters used in the
+// Chinese notation to the same form, so that the notations written in the
+// simplified or traditional Chinese, and with either kind of numerals can
+// be compared.
+var chineseNormalizer = strings.NewReplacer(
+	"帥", "K", "帅", "K", "将", "K", "將", "K",
+	"仕", "A", "士", "A",
+	"相", "E", "象", "E",
+	"馬", "H", "马", "H", "傌", "H", "駒", "H",
+	"車", "R", "车", "R", "俥", "R",
+	"炮", "C", "砲", "C", "包", "C",
+	"兵", "P", "卒", "P",
+	"进", "+", "進", "+",
+	"退", "-",
+	"平", "=",
+	"前", "F", "中", "M", "后", "B", "後", "B",
+	"一", "1", "二", "2", "三", "3", "四", "4", "五", "5",
+	"六", "6", "七", "7", "八", "8", "九", "9",
+	"１", "1", "２", "2", "３", "3", "４", "4", "５", "5",
+	"６", "6", "７", "7", "８", "8", "９", "9",
+	" ", "",
+)
+
+// Chinese returns the move in the traditional Chinese notation, e.g.
+// "炮二平五" or "馬8進7". The move must be a legal move in the current
+// position.
+//
+// Tandem pieces on the same file are denoted by "前" (front) and "後"
+// (rear), and "中" (middle) if there are three soldiers; four or more
+// soldiers are numbered from the front. When soldiers are in tandem on
+// more than one file, the piece name is replaced by the file number,
+// e.g. "前七進一".
+func (b *Board) Chinese(m Move) string {
+	n := b.describeMove(m)
+	numerals := redNumerals
+	if m.Piece.color == Black {
+		numerals = blackNumerals
+	}
+
+	var sb strings.Builder
+	if n.tandemIndex < 0 {
+		sb.WriteString(chineseNameMap[m.Piece])
+		sb.WriteString(numerals[n.file])
+	} else {
+		sb.WriteString(chineseTandemMarker(n.tandemIndex, n.tandemCount, numerals))
+		if n.multiFile {
+			sb.WriteString(numerals[n.file])
+		} else {
+			sb.WriteString(chineseNameMap[m.Piece])
+		}
+	}
+
+	switch n.direction {
+	case 1:
+		sb.WriteString("進")
+	case -1:
+		sb.WriteString("退")
+	default:
+		sb.WriteString("平")
+	}
+	sb.WriteString(numerals[n.num])
+
+	return sb.String()
+}
+
+func chineseTandemMarker(index, count int, numerals []string) string {
+	switch {
+	case count == 2 && index == 0:
+		return "前"
+	case count == 2:
+		return "後"
+	case count == 3 && index == 0:
+		return "前"
+	case count == 3 && index == 1:
+		return "中"
+	case count == 3:
+		return "後"
+	}
+	return numerals[index+1]
+}
+
+// ParseChinese parses a move in the Chinese notation, and returns the
+// legal move in the current position. Both the simplified and traditional
+// characters, and both kinds of numerals are accepted.
+func (b *Board) ParseChinese(s string) (Move, error) {
+	str := chineseNormalizer.Replace(strings.TrimSpace(s))
+	for _, m := range b.LegalMoves() {
+		if chineseNormalizer.Replace(b.Chinese(m)) == str {
+			return m, nil
+		}
+	}
+	return Move{}, fmt.Errorf("invalid or illegal Chinese move %q", s)
+}
@@ -0,0 +1,137 @@
+package rules
+
+import "testing"
+
+func TestChinese(t *testing.T) {
+	const blackStartFEN = "rnbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C5C1/9/RNBAKABNR b - - 0 1"
+	testCases := []struct {
+		fen  string
+		iccs string
+		want string
+	}{
+		{StartFEN, "h2e2", "炮二平五"},
+		{StartFEN, "h0g2", "馬二進三"},
+		{StartFEN, "c0e2", "相七進五"},
+		{StartFEN, "f0e1", "仕四進五"},
+		{StartFEN, "e0e1", "帥五進一"},
+		{StartFEN, "c3c4", "兵七進一"},
+		{blackStartFEN, "h9g7", "馬8進7"},
+		{blackStartFEN, "b7e7", "炮2平5"},
+		{blackStartFEN, "e6e5", "卒5進1"},
+		{blackStartFEN, "d9e8", "士4進5"},
+
+		// the tandem rooks
+		{tandemRooksFEN, "e4e6", "前車進二"},
+		{tandemRooksFEN, "e1e3", "後車進二"},
+		{tandemRooksFEN, "e4e2", "前車退二"},
+		{tandemRooksFEN, "e1d1", "後車平六"},
+		{"3k5/2r6/9/9/2r6/4R4/9/9/4R4/5K3 b - - 0 1", "c5c4", "前車進1"},
+		{"3k5/2r6/9/9/2r6/4R4/9/9/4R4/5K3 b - - 0 1", "c8c9", "後車退1"},
+
+		// the soldiers on the same file
+		{twoSoldiersFEN, "e6e7", "前兵進一"},
+		{twoSoldiersFEN, "e5d5", "後兵平六"},
+		{threeSoldiersFEN, "e7e8", "前兵進一"},
+		{threeSoldiersFEN, "e6d6", "中兵平六"},
+		{threeSoldiersFEN, "e5f5", "後兵平四"},
+		{fourSoldiersFEN, "e8e9", "一兵進一"},
+		{fourSoldiersFEN, "e7d7", "二兵平六"},
+		{fourSoldiersFEN, "e6f6", "三兵平四"},
+		{fourSoldiersFEN, "e5d5", "四兵平六"},
+
+		// the soldiers in tandem on more than one file
+		{multiFileSoldiersFEN, "c6c7", "前七進一"},
+		{multiFileSoldiersFEN, "e5f5", "後五平四"},
+		{blackSoldiersFEN, "e3e2", "前5進1"},
+		{blackSoldiersFEN, "e4d4", "後5平4"},
+		{blackSoldiersFEN, "g2g1", "前7進1"},
+		{blackSoldiersFEN, "g3h3", "中7平8"},
+		{blackSoldiersFEN, "g4f4", "後7平6"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.want, func(t *testing.T) {
+			b, err := ParseFEN(tc.fen)
+			if err != nil {
+				t.Fatalf("Failed to parse the FEN: %v", err)
+			}
+			m, err := b.ParseICCS(tc.iccs)
+			if err != nil {
+				t.Fatalf("Failed to parse %s: %v", tc.iccs, err)
+			}
+			if got := b.Chinese(m); got != tc.want {
+				t.Errorf("Unexpected Chinese notation of %s, want: %s, got: %s", tc.iccs, tc.want, got)
+			}
+			if got, err := b.ParseChinese(tc.want); err != nil || got != m {
+				t.Errorf("Unexpected move parsed from %s, want: %s, got: %s (err: %v)", tc.want, tc.iccs, got.ICCS(), err)
+			}
+		})
+	}
+}
+
+func TestParseChinese(t *testing.T) {
+	testCases := []struct {
+		fen  string
+		s    string
+		iccs string
+	}{
+		// the simplified characters
+		{StartFEN, "车一进一", "i0i1"},
+		{StartFEN, "马二进三", "h0g2"},
+		{StartFEN, "帅五进一", "e0e1"},
+		{tandemRooksFEN, "前车进二", "e4e6"},
+		{tandemRooksFEN, "后车平六", "e1d1"},
+		{threeSoldiersFEN, "后兵平四", "e5f5"},
+		{"rnbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C5C1/9/RNBAKABNR b - - 0 1", "将5进1", "e9e8"},
+
+		// the variants of the characters and the numerals
+		{StartFEN, "傌二進三", "h0g2"},
+		{StartFEN, "俥九進一", "a0a1"},
+		{StartFEN, "砲2平5", "h2e2"},
+		{StartFEN, " 炮二平五 ", "h2e2"},
+		{"rnbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C5C1/9/RNBAKABNR b - - 0 1", "马８进７", "h9g7"},
+		{"rnbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C5C1/9/RNBAKABNR b - - 0 1", "包二平五", "b7e7"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.s, func(t *testing.T) {
+			b, err := ParseFEN(tc.fen)
+			if err != nil {
+				t.Fatalf("Failed to parse the FEN: %v", err)
+			}
+			m, err := b.ParseChinese(tc.s)
+			if err != nil {
+				t.Fatalf("Failed to parse %q: %v", tc.s, err)
+			}
+			if got := m.ICCS(); got != tc.iccs {
+				t.Errorf("Unexpected move parsed from %q, want: %s, got: %s", tc.s, tc.iccs, got)
+			}
+		})
+	}
+
+	b := NewBoard()
+	for _, s := range []string{"炮二進九", "車一進三", "兵二進一", ""} {
+		if _, err := b.ParseChinese(s); err == nil {
+			t.Errorf("Expected an error parsing %q", s)
+		}
+	}
+}
+
+func TestChineseRoundTrip(t *testing.T) {
+	for _, fen := range notationPositions {
+		b, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf("Failed to parse the FEN: %v", err)
+		}
+		seen := map[string]Move{}
+		for _, m := range b.LegalMoves() {
+			s := b.Chinese(m)
+			if other, ok := seen[s]; ok {
+				t.Errorf("Ambiguous Chinese notation %s of %s and %s in %s", s, m.ICCS(), other.ICCS(), fen)
+			}
+			seen[s] = m
+			if got, err := b.ParseChinese(s); err != nil || got != m {
+				t.Errorf("Unexpected move parsed from %s in %s, want: %s, got: %s (err: %v)", s, fen, m.ICCS(), got.ICCS(), err)
+			}
+		}
+	}
+}
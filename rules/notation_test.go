@@ -0,0 +1,146 @@
+package rules
+
+import "testing"
+
+// The positions of the notation tests, which have the tandem pieces.
+const (
+	// The red rooks on the file 5, and the black rooks on the file 3.
+	tandemRooksFEN = "3k5/2r6/9/9/2r6/4R4/9/9/4R4/5K3 w - - 0 1"
+	// Two red soldiers on the file 5.
+	twoSoldiersFEN = "3k5/9/9/4P4/4P4/9/9/9/9/5K3 w - - 0 1"
+	// Three red soldiers on the file 5.
+	threeSoldiersFEN = "3k5/9/4P4/4P4/4P4/9/9/9/9/5K3 w - - 0 1"
+	// Four red soldiers on the file 5, which are numbered from the front.
+	fourSoldiersFEN = "3k5/4P4/4P4/4P4/4P4/9/9/9/9/5K3 w - - 0 1"
+	// Two red soldiers on each of the files 7 and 5.
+	multiFileSoldiersFEN = "3k5/9/9/2P1P4/2P1P4/9/9/9/9/5K3 w - - 0 1"
+	// Two black soldiers on the file 5, and three on the file 7.
+	blackSoldiersFEN = "3k5/9/9/9/9/4p1p2/4p1p2/6p2/9/5K3 b - - 0 1"
+)
+
+func TestWXF(t *testing.T) {
+	testCases := []struct {
+		fen  string
+		iccs string
+		want string
+	}{
+		{StartFEN, "h2e2", "C2=5"},
+		{StartFEN, "h0g2", "H2+3"},
+		{StartFEN, "c0e2", "E7+5"},
+		{StartFEN, "f0e1", "A4+5"},
+		{StartFEN, "a0a1", "R9+1"},
+		{StartFEN, "e0e1", "K5+1"},
+		{StartFEN, "c3c4", "P7+1"},
+		{"rnbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C5C1/9/RNBAKABNR b - - 0 1", "h9g7", "H8+7"},
+		{"rnbakabnr/9/1c5c1/p1p1p1p1p/9/9/P1P1P1P1P/1C5C1/9/RNBAKABNR b - - 0 1", "b7e7", "C2=5"},
+
+		// the tandem rooks
+		{tandemRooksFEN, "e4e6", "+R+2"},
+		{tandemRooksFEN, "e1e3", "-R+2"},
+		{tandemRooksFEN, "e1d1", "-R=6"},
+		{tandemRooksFEN, "e4e2", "+R-2"},
+		{"3k5/2r6/9/9/2r6/4R4/9/9/4R4/5K3 b - - 0 1", "c5c4", "+R+1"},
+		{"3k5/2r6/9/9/2r6/4R4/9/9/4R4/5K3 b - - 0 1", "c8c9", "-R-1"},
+
+		// the soldiers on the same file
+		{twoSoldiersFEN, "e6e7", "+P+1"},
+		{twoSoldiersFEN, "e5d5", "-P=6"},
+		{threeSoldiersFEN, "e7e8", "1P+1"},
+		{threeSoldiersFEN, "e6d6", "2P=6"},
+		{threeSoldiersFEN, "e5f5", "3P=4"},
+		{fourSoldiersFEN, "e8e9", "1P+1"},
+		{fourSoldiersFEN, "e5d5", "4P=6"},
+
+		// the soldiers in tandem on more than one file
+		{multiFileSoldiersFEN, "c6c7", "+7+1"},
+		{multiFileSoldiersFEN, "c5b5", "-7=8"},
+		{multiFileSoldiersFEN, "e6e7", "+5+1"},
+		{multiFileSoldiersFEN, "e5f5", "-5=4"},
+		{blackSoldiersFEN, "e3e2", "+5+1"},
+		{blackSoldiersFEN, "g2g1", "17+1"},
+		{blackSoldiersFEN, "g3h3", "27=8"},
+		{blackSoldiersFEN, "g4f4", "37=6"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.want, func(t *testing.T) {
+			b, err := ParseFEN(tc.fen)
+			if err != nil {
+				t.Fatalf("Failed to parse the FEN: %v", err)
+			}
+			m, err := b.ParseICCS(tc.iccs)
+			if err != nil {
+				t.Fatalf("Failed to parse %s: %v", tc.iccs, err)
+			}
+			if got := b.WXF(m); got != tc.want {
+				t.Errorf("Unexpected WXF of %s, want: %s, got: %s", tc.iccs, tc.want, got)
+			}
+			if got, err := b.ParseWXF(tc.want); err != nil || got != m {
+				t.Errorf("Unexpected move parsed from %s, want: %s, got: %s (err: %v)", tc.want, tc.iccs, got.ICCS(), err)
+			}
+		})
+	}
+}
+
+func TestParseWXF(t *testing.T) {
+	b := NewBoard()
+	testCases := []struct {
+		s    string
+		iccs string
+	}{
+		{"C2.5", "h2e2"},
+		{"c2=5", "h2e2"},
+		{"N2+3", "h0g2"},
+		{"B7+5", "c0e2"},
+		{" R9+1 ", "a0a1"},
+	}
+	for _, tc := range testCases {
+		m, err := b.ParseWXF(tc.s)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v", tc.s, err)
+			continue
+		}
+		if got := m.ICCS(); got != tc.iccs {
+			t.Errorf("Unexpected move parsed from %q, want: %s, got: %s", tc.s, tc.iccs, got)
+		}
+	}
+
+	for _, s := range []string{"C2+8", "R1+3", "X2=5", ""} {
+		if _, err := b.ParseWXF(s); err == nil {
+			t.Errorf("Expected an error parsing %q", s)
+		}
+	}
+}
+
+// notationPositions are the positions to check the round trips of all the
+// legal moves.
+var notationPositions = []string{
+	StartFEN,
+	tandemRooksFEN,
+	twoSoldiersFEN,
+	threeSoldiersFEN,
+	fourSoldiersFEN,
+	multiFileSoldiersFEN,
+	blackSoldiersFEN,
+	"r1ba1a3/4kn3/2n1b4/pNp1p1p1p/4c4/6P2/P1P2R2P/1CcC5/9/2BAKAB2 w - - 0 1",
+}
+
+func TestWXFRoundTrip(t *testing.T) {
+	for _, fen := range notationPositions {
+		b, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf("Failed to parse the FEN: %v", err)
+		}
+		seen := map[string]Move{}
+		for _, m := range b.LegalMoves() {
+			s := b.WXF(m)
+			if other, ok := seen[s]; ok {
+				t.Errorf("Ambiguous WXF %s of %s and %s in %s", s, m.ICCS(), other.ICCS(), fen)
+			}
+			seen[s] = m
+			if got, err := b.ParseWXF(s); err != nil || got != m {
+				t.Errorf("Unexpected move parsed from %s in %s, want: %s, got: %s (err: %v)", s, fen, m.ICCS(), got.ICCS(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package rules
+
+// Perft counts the leaf nodes of the legal move tree of the specified
+// depth. It's used to verify the correctness of the move generator by
+// comparing the counts with the published numbers.
+func (b *Board) Perft(depth int) int {
+	if depth <= 0 {
+		return 1
+	}
+
+	moves := b.legalMoves(nil)
+	if depth == 1 {
+		return len(moves)
+	}
+
+	nodes := 0
+	for _, mv := range moves {
+		b.makeMove(mv)
+		nodes += b.Perft(depth - 1)
+		b.unmakeMove()
+	}
+	return nodes
+}
+
+// PerftEntry is the node count of the subtree under a root move.
+type PerftEntry struct {
+	Move  Move
+	Nodes int
+}
+
+// Divide is the same as Perft, but it returns the node count under each
+// root move, so that a wrong count can be narrowed down to a move.
+func (b *Board) Divide(depth int) []PerftEntry {
+	var entries []PerftEntry
+	for _, mv := range b.legalMoves(nil) {
+		m := b.toMove(mv)
+		b.makeMove(mv)
+		entries = append(entries, PerftEntry{Move: m, Nodes: b.Perft(depth - 1)})
+		b.unmakeMove()
+	}
+	return entries
+}
@@ -0,0 +1,282 @@
+package rules
+
+import (
+	"image"
+	"testing"
+)
+
+// The perft numbers of the start position and the positions 2~6 are the
+// published ones on the "Chinese Chess Perft Results" page of the Chess
+// Programming Wiki. The numbers of the other positions are the same as the
+// ones of the naive generator (see referenceBoard), and the depth 1 ones
+// are verified by hand as well.
+var perftCases = []struct {
+	name  string
+	fen   string
+	nodes []int // nodes[i] is the perft count of depth i+1
+}{
+	{
+		name:  "start position",
+		fen:   StartFEN,
+		nodes: []int{44, 1920, 79666, 3290240},
+	},
+	{
+		name:  "position 2",
+		fen:   "r1ba1a3/4kn3/2n1b4/pNp1p1p1p/4c4/6P2/P1P2R2P/1CcC5/9/2BAKAB2 w - - 0 1",
+		nodes: []int{38, 1128, 43929, 1339047},
+	},
+	{
+		name:  "position 3",
+		fen:   "1cbak4/9/n2a5/2p1p3p/5cp2/2n2N3/6PCP/3AB4/2C6/3A1K1N1 w - - 0 1",
+		nodes: []int{7, 281, 8620, 326201},
+	},
+	{
+		name:  "position 4",
+		fen:   "5a3/3k5/3aR4/9/5r3/5n3/9/3A1A3/5K3/2BC2B2 w - - 0 1",
+		nodes: []int{25, 424, 9850, 202884},
+	},
+	{
+		name:  "position 5",
+		fen:   "CRN1k1b2/3ca4/4ba3/9/2nr5/9/9/4B4/4A4/4KA3 w - - 0 1",
+		nodes: []int{28, 516, 14808, 395483},
+	},
+	{
+		name:  "position 6",
+		fen:   "R1N1k1b2/9/3aba3/9/2nr5/2B6/9/4B4/4A4/4KA3 w - - 0 1",
+		nodes: []int{21, 364, 7626, 162837},
+	},
+	{
+		// The red king can't move to the file e, where it would face
+		// the black king directly.
+		name:  "flying general",
+		fen:   "4k4/9/9/9/9/9/9/9/9/3K5 w - - 0 1",
+		nodes: []int{1, 2, 5, 11, 17},
+	},
+	{
+		// The soldier blocks the forward legs of the horse, and the king
+		// can move to the file e because the pieces are in between.
+		name:  "blocked horse legs",
+		fen:   "4k4/9/9/9/9/9/9/4P4/4N4/3K5 w - - 0 1",
+		nodes: []int{7, 15, 115, 298, 2455},
+	},
+	{
+		// The cannon captures the rook over the soldier, but can't move
+		// beyond the soldier.
+		name:  "cannon screen",
+		fen:   "3k5/4r4/9/9/4p4/9/9/4C4/9/5K3 w - - 0 1",
+		nodes: []int{15, 198, 3043, 47724, 738804},
+	},
+	{
+		// The kings are on the same file, so the pieces in between can't
+		// all leave it, and the horses are blocked by the pieces around.
+		name:  "kings on the same file",
+		fen:   "4k4/4a4/4c4/9/2n1R4/9/2N1P4/4C4/4A4/4K4 w - - 0 1",
+		nodes: []int{31, 724, 23753, 478222},
+	},
+}
+
+func TestPerft(t *testing.T) {
+	for _, tc := range perftCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := ParseFEN(tc.fen)
+			if err != nil {
+				t.Fatalf("Failed to parse the FEN: %v", err)
+			}
+			for i, want := range tc.nodes {
+				depth := i + 1
+				if testing.Short() && want > 100000 {
+					t.Skipf("Skipping depth %d in short mode", depth)
+				}
+				if got := b.Perft(depth); got != want {
+					t.Errorf("Unexpected perft(%d), want: %d, got: %d", depth, want, got)
+				}
+			}
+			if got := b.FEN(); got != tc.fen {
+				t.Errorf("The board isn't restored after perft, want: %s, got: %s", tc.fen, got)
+			}
+		})
+	}
+}
+
+func TestDivide(t *testing.T) {
+	b := NewBoard()
+
+	entries := b.Divide(3)
+	if len(entries) != 44 {
+		t.Errorf("Unexpected number of root moves, want: %d, got: %d", 44, len(entries))
+	}
+	total := 0
+	for _, e := range entries {
+		total += e.Nodes
+	}
+	if total != 79666 {
+		t.Errorf("Unexpected divide total, want: %d, got: %d", 79666, total)
+	}
+}
+
+// referenceBoard is a naive move generator on a plain 10*9 array, which
+// shares nothing with the engine but the FEN parser. It tries every pair
+// of the points against the rules of the pieces, and it's only used to
+// cross-check the perft counts of the engine.
+type referenceBoard struct {
+	cells [10][9]Piece // the zero Piece if empty
+	color PieceColor
+}
+
+func newReferenceBoard(b *Board) *referenceBoard {
+	r := &referenceBoard{color: b.color()}
+	for i := 0; i <= 9; i++ {
+		for j := 0; j <= 8; j++ {
+			if p, ok := b.pieceAt(i, j); ok {
+				r.cells[i][j] = p
+			}
+		}
+	}
+	return r
+}
+
+func (r *referenceBoard) empty(x, y int) bool {
+	return r.cells[x][y] == Piece{}
+}
+
+// between counts the pieces strictly between two points on the same line.
+func (r *referenceBoard) between(x1, y1, x2, y2 int) int {
+	dx, dy := sign(x2-x1), sign(y2-y1)
+	n := 0
+	for x, y := x1+dx, y1+dy; x != x2 || y != y2; x, y = x+dx, y+dy {
+		if !r.empty(x, y) {
+			n++
+		}
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
+
+// canMove checks whether the piece on (x1, y1) can move to (x2, y2) by
+// the rules of its role, regardless of the safety of its own king.
+func (r *referenceBoard) canMove(x1, y1, x2, y2 int) bool {
+	p, target := r.cells[x1][y1], r.cells[x2][y2]
+	if (x1 == x2 && y1 == y2) || target.color == p.color {
+		return false
+	}
+	dx, dy := x2-x1, y2-y1
+	adx, ady := dx*sign(dx), dy*sign(dy)
+	switch p.role {
+	case RoleKing:
+		return adx+ady == 1 && isInPalace(x2, y2, p.color)
+	case RoleGuard:
+		return adx == 1 && ady == 1 && isInPalace(x2, y2, p.color)
+	case RoleBishop:
+		return adx == 2 && ady == 2 && isOwnSide(x2, p.color) && r.empty(x1+dx/2, y1+dy/2)
+	case RoleHorse:
+		switch {
+		case adx == 2 && ady == 1:
+			return r.empty(x1+dx/2, y1)
+		case adx == 1 && ady == 2:
+			return r.empty(x1, y1+dy/2)
+		}
+		return false
+	case RoleRook:
+		return (dx == 0 || dy == 0) && r.between(x1, y1, x2, y2) == 0
+	case RoleCannon:
+		if dx != 0 && dy != 0 {
+			return false
+		}
+		if target == (Piece{}) {
+			return r.between(x1, y1, x2, y2) == 0
+		}
+		return r.between(x1, y1, x2, y2) == 1
+	case RoleSolder:
+		if dx == forward(p.color) && dy == 0 {
+			return true
+		}
+		return !isOwnSide(x1, p.color) && dx == 0 && ady == 1
+	}
+	return false
+}
+
+// isSafe checks whether the king of the color is neither attacked by any
+// opponent's piece nor facing the other king.
+func (r *referenceBoard) isSafe(color PieceColor) bool {
+	var kings [2]image.Point
+	for i := 0; i <= 9; i++ {
+		for j := 0; j <= 8; j++ {
+			if r.cells[i][j].role == RoleKing {
+				kings[colorIndex(r.cells[i][j].color)] = image.Point{X: i, Y: j}
+			}
+		}
+	}
+	king := kings[colorIndex(color)]
+	if other := kings[1-colorIndex(color)]; king.Y == other.Y && r.between(king.X, king.Y, other.X, other.Y) == 0 {
+		return false
+	}
+	for i := 0; i <= 9; i++ {
+		for j := 0; j <= 8; j++ {
+			if p := r.cells[i][j]; p != (Piece{}) && p.color != color && r.canMove(i, j, king.X, king.Y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r *referenceBoard) perft(depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	nodes := 0
+	for x1 := 0; x1 <= 9; x1++ {
+		for y1 := 0; y1 <= 8; y1++ {
+			if r.cells[x1][y1].color != r.color {
+				continue
+			}
+			for x2 := 0; x2 <= 9; x2++ {
+				for y2 := 0; y2 <= 8; y2++ {
+					if !r.canMove(x1, y1, x2, y2) {
+						continue
+					}
+					p, captured := r.cells[x1][y1], r.cells[x2][y2]
+					r.cells[x2][y2], r.cells[x1][y1] = p, Piece{}
+					if r.isSafe(p.color) {
+						r.color = opponent(p.color)
+						nodes += r.perft(depth - 1)
+						r.color = p.color
+					}
+					r.cells[x1][y1], r.cells[x2][y2] = p, captured
+				}
+			}
+		}
+	}
+	return nodes
+}
+
+func TestPerftReference(t *testing.T) {
+	for _, tc := range perftCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := ParseFEN(tc.fen)
+			if err != nil {
+				t.Fatalf("Failed to parse the FEN: %v", err)
+			}
+			r := newReferenceBoard(b)
+			// The naive generator is slow, so it only checks the counts
+			// of the small trees.
+			for i, want := range tc.nodes {
+				if want > 50000 {
+					break
+				}
+				if got := r.perft(i + 1); got != want {
+					t.Errorf("Unexpected reference perft(%d), want: %d, got: %d", i+1, want, got)
+				}
+			}
+		})
+	}
+}
@@ -29,3 +29,15 @@ type Piece struct {
 func (p Piece) String() string {
 	return fmt.Sprintf("(%s, %s)", p.color, p.role)
 }
+
+func NewPiece(color PieceColor, role PieceRole) Piece {
+	return Piece{color: color, role: role}
+}
+
+func (p Piece) Color() PieceColor {
+	return p.color
+}
+
+func (p Piece) Role() PieceRole {
+	return p.role
+}
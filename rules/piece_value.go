@@ -9,3 +9,12 @@ var pieceValueMap map[PieceRole]int = map[PieceRole]int{
 	RoleGuard:  2,
 	RoleSolder: 1,
 }
+
+// pieceValues is the same as pieceValueMap, but indexed by the role index
+// of the engine, which is much faster to look up.
+var pieceValues = func() (values [7]int) {
+	for i, role := range roleOfIndex {
+		values[i] = pieceValueMap[role]
+	}
+	return values
+}()
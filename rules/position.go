@@ -0,0 +1,336 @@
+package rules
+
+import (
+	"fmt"
+	"image"
+)
+
+// The engine uses a padded mailbox of 16*16 squares, which is much more
+// compact than a matrix of pointers, and the moves can be generated by
+// adding the deltas without checking the boundary of the rows and columns.
+// The 10*9 board occupies the rows 3~12 and the columns 3~11, so that any
+// step of any piece (at most 2 rows or columns) from a square on the board
+// is still inside the mailbox.
+//
+// Each piece has a fixed slot (16~47), and the mailbox stores the slot of
+// the piece on each square, so the slots work as the piece lists as well.
+// The Red pieces use the slots 16~31, and the Black pieces use 32~47. The
+// first slot of each side is always the king, so the king square is cached.
+
+// square is the index (0~255) of a square in the mailbox.
+type square = int
+
+const (
+	boardTop  = 3
+	boardLeft = 3
+
+	redTag   = 16
+	blackTag = 32
+
+	// the delta of the squares of one step to each direction
+	stepUp    = -16
+	stepDown  = 16
+	stepLeft  = -1
+	stepRight = 1
+)
+
+// The roles of the pieces inside the engine.
+const (
+	kingIndex = iota
+	guardIndex
+	bishopIndex
+	horseIndex
+	rookIndex
+	cannonIndex
+	soldierIndex
+)
+
+var (
+	roleOfIndex = [7]PieceRole{RoleKing, RoleGuard, RoleBishop, RoleHorse, RoleRook, RoleCannon, RoleSolder}
+
+	// roleIndexMap maps the role to its index inside the engine.
+	roleIndexMap = map[PieceRole]int{
+		RoleKing:   kingIndex,
+		RoleGuard:  guardIndex,
+		RoleBishop: bishopIndex,
+		RoleHorse:  horseIndex,
+		RoleRook:   rookIndex,
+		RoleCannon: cannonIndex,
+		RoleSolder: soldierIndex,
+	}
+
+	// slotRoles maps the slot (0~15) of a side to the role index. Note a
+	// side has at most 16 pieces in any legal position, because there is no
+	// promotion in Chinese chess.
+	slotRoles = [16]int{
+		kingIndex,
+		guardIndex, guardIndex,
+		bishopIndex, bishopIndex,
+		horseIndex, horseIndex,
+		rookIndex, rookIndex,
+		cannonIndex, cannonIndex,
+		soldierIndex, soldierIndex, soldierIndex, soldierIndex, soldierIndex,
+	}
+
+	// inBoard tells whether the square is on the 10*9 board.
+	inBoard [256]bool
+)
+
+func init() {
+	for i := 0; i <= 9; i++ {
+		for j := 0; j <= 8; j++ {
+			inBoard[toSquare(i, j)] = true
+		}
+	}
+}
+
+// toSquare converts the row (0~9) and column (0~8) to the square.
+func toSquare(x, y int) square {
+	return (x+boardTop)<<4 | (y + boardLeft)
+}
+
+// pointOf converts the square to the point, whose X is the row number
+// and Y is the column number.
+func pointOf(sq square) image.Point {
+	return image.Point{X: sq>>4 - boardTop, Y: sq&15 - boardLeft}
+}
+
+func rowOf(sq square) int {
+	return sq>>4 - boardTop
+}
+
+func colOf(sq square) int {
+	return sq&15 - boardLeft
+}
+
+// sideTag returns the first slot of the color.
+func sideTag(color PieceColor) int {
+	if color == Red {
+		return redTag
+	}
+	return blackTag
+}
+
+// slotColor returns the color of the piece of the slot.
+func slotColor(slot int) PieceColor {
+	if slot&redTag != 0 {
+		return Red
+	}
+	return Black
+}
+
+// slotRole returns the role index of the piece of the slot.
+func slotRole(slot int) int {
+	return slotRoles[slot&15]
+}
+
+// pieceIndex returns the index (0~13) of the piece of the slot, which is
+// the role index for the Red pieces, and the role index + 7 for the Black
+// pieces.
+func pieceIndex(slot int) int {
+	if slot&blackTag != 0 {
+		return slotRole(slot) + 7
+	}
+	return slotRole(slot)
+}
+
+// slotPiece returns the piece of the slot.
+func slotPiece(slot int) Piece {
+	return Piece{slotColor(slot), roleOfIndex[slotRole(slot)]}
+}
+
+// move is a move inside the engine, the low byte is the source square,
+// and the high byte is the destination square.
+type move uint16
+
+func newMove(from, to square) move {
+	return move(from | to<<8)
+}
+
+func (mv move) from() square {
+	return square(mv & 0xff)
+}
+
+func (mv move) to() square {
+	return square(mv >> 8)
+}
+
+// position is the representation of a position inside the engine.
+type position struct {
+	// The slot of the piece on each square, or 0 if the square is empty.
+	squares [256]uint8
+	// The square of the piece of each slot, or 0 if the piece isn't on the board.
+	pieces [48]uint8
+	// Is it the Red's turn to move? Always defaults to true in the beginning.
+	isRedTurn bool
+	// The number of half moves since the last capture.
+	halfMoveClock int
+	// The number of the full moves. It starts at 1, and is incremented
+	// after each Black's move.
+	fullMoveNumber int
+	// The Zobrist hash of the position.
+	hash uint64
+	// The occupancy of each row and column, see toggleOccupancy.
+	rowBits [10]uint16
+	colBits [9]uint16
+	// The moves made so far, which can be taken back one by one.
+	undoStack []undoEntry
+}
+
+// undoEntry records what's needed to take back a move.
+type undoEntry struct {
+	mv move
+	// the slot of the captured piece, or 0
+	captured uint8
+	// the half move clock before the move
+	halfMoveClock int
+	// the hash before the move
+	hash uint64
+}
+
+// addPiece puts the piece on the empty square, and assigns a free slot of
+// its role to it. It returns an error if all the slots of the role are
+// taken, which means there are too many pieces of the role.
+func (p *position) addPiece(sq square, piece Piece) error {
+	role := roleIndexMap[piece.role]
+	tag := sideTag(piece.color)
+	for i, r := range slotRoles {
+		slot := tag + i
+		if r != role || p.pieces[slot] != 0 {
+			continue
+		}
+		p.squares[sq] = uint8(slot)
+		p.pieces[slot] = uint8(sq)
+		p.hash ^= zobristKey(slot, sq)
+		p.toggleOccupancy(sq)
+		return nil
+	}
+	return fmt.Errorf("too many %s %ss", piece.color, piece.role)
+}
+
+// pieceAt returns the piece on the point, and false if the point is empty.
+func (p *position) pieceAt(x, y int) (Piece, bool) {
+	slot := p.squares[toSquare(x, y)]
+	if slot == 0 {
+		return Piece{}, false
+	}
+	return slotPiece(int(slot)), true
+}
+
+// kingSquare returns the square of the king of the color.
+func (p *position) kingSquare(color PieceColor) square {
+	return square(p.pieces[sideTag(color)])
+}
+
+// makeMove moves the piece in place and switches the active side. The
+// captured piece is recorded on the undo stack, so that the move can be
+// taken back by unmakeMove.
+func (p *position) makeMove(mv move) {
+	from, to := mv.from(), mv.to()
+	slot, captured := int(p.squares[from]), p.squares[to]
+	p.undoStack = append(p.undoStack, undoEntry{
+		mv:            mv,
+		captured:      captured,
+		halfMoveClock: p.halfMoveClock,
+		hash:          p.hash,
+	})
+
+	p.hash ^= zobristKey(slot, from) ^ zobristKey(slot, to) ^ zobristBlackKey
+	if captured != 0 {
+		p.hash ^= zobristKey(int(captured), to)
+		p.pieces[captured] = 0
+		p.halfMoveClock = 0
+	} else {
+		p.halfMoveClock++
+		p.toggleOccupancy(to)
+	}
+	p.squares[to] = uint8(slot)
+	p.squares[from] = 0
+	p.pieces[slot] = uint8(to)
+	p.toggleOccupancy(from)
+
+	p.switchPlayer()
+}
+
+// unmakeMove takes back the last move made by makeMove.
+func (p *position) unmakeMove() {
+	e := p.undoStack[len(p.undoStack)-1]
+	p.undoStack = p.undoStack[:len(p.undoStack)-1]
+
+	from, to := e.mv.from(), e.mv.to()
+	slot := p.squares[to]
+	p.squares[from] = slot
+	p.pieces[slot] = uint8(from)
+	p.squares[to] = e.captured
+	if e.captured != 0 {
+		p.pieces[e.captured] = uint8(to)
+	} else {
+		p.toggleOccupancy(to)
+	}
+	p.toggleOccupancy(from)
+	p.halfMoveClock = e.halfMoveClock
+	p.hash = e.hash
+
+	p.isRedTurn = !p.isRedTurn
+	if !p.isRedTurn {
+		p.fullMoveNumber--
+	}
+}
+
+// makeNullMove passes the turn to the opponent without moving any piece,
+// which is only used by the null move pruning of the search.
+func (p *position) makeNullMove() {
+	p.undoStack = append(p.undoStack, undoEntry{
+		halfMoveClock: p.halfMoveClock,
+		hash:          p.hash,
+	})
+	p.hash ^= zobristBlackKey
+	p.switchPlayer()
+}
+
+// unmakeNullMove takes back the last null move made by makeNullMove.
+func (p *position) unmakeNullMove() {
+	e := p.undoStack[len(p.undoStack)-1]
+	p.undoStack = p.undoStack[:len(p.undoStack)-1]
+	p.hash = e.hash
+
+	p.isRedTurn = !p.isRedTurn
+	if !p.isRedTurn {
+		p.fullMoveNumber--
+	}
+}
+
+// isAfterNullMove checks whether the last move is a null move.
+func (p *position) isAfterNullMove() bool {
+	n := len(p.undoStack)
+	return n > 0 && p.undoStack[n-1].mv == 0
+}
+
+func (p *position) switchPlayer() {
+	if !p.isRedTurn {
+		p.fullMoveNumber++
+	}
+	p.isRedTurn = !p.isRedTurn
+}
+
+// `color` returns the color of the current active side.
+func (p *position) color() PieceColor {
+	if p.isRedTurn {
+		return Red
+	}
+	return Black
+}
+
+// toMove converts the move of the engine to the public Move. It must
+// be called before the move is made.
+func (p *position) toMove(mv move) Move {
+	m := Move{
+		From:  pointOf(mv.from()),
+		To:    pointOf(mv.to()),
+		Piece: slotPiece(int(p.squares[mv.from()])),
+	}
+	if captured := p.squares[mv.to()]; captured != 0 {
+		m.Captured = slotPiece(int(captured))
+	}
+	return m
+}
@@ -0,0 +1,217 @@
+package rules
+
+// RuleSet decides how the repeated positions are judged.
+type RuleSet string
+
+const (
+	// RuleSetAXF follows the rules of the Asian Xiangqi Federation (亚洲象棋规则).
+	RuleSetAXF = RuleSet("axf")
+	// RuleSetChinese follows the rules of the Chinese Xiangqi Association (中国象棋竞赛规则).
+	RuleSetChinese = RuleSet("chinese")
+)
+
+// The game ends once the same position occurs for the third time.
+const repetitionLimit = 3
+
+// historyEntry records a position of the game, and how the move leading to
+// the position was made.
+type historyEntry struct {
+	hash uint64
+	// Whether the move captured a piece. The positions before a capture
+	// can never occur again.
+	capture bool
+	// Whether the move checked the opponent's king (将).
+	check bool
+	// Whether the move chased an opponent's piece (捉).
+	chase bool
+}
+
+// The repetitions are judged in a simplified way, which is shared by both
+// rule sets: once a position occurs for the third time, a side violates
+// the rules if each of its moves since the first occurrence is either a
+// check or a chase. It's a perpetual check (长将) if all the moves are
+// checks, or otherwise a perpetual chase (长捉), including the alternating
+// one (一将一捉). The perpetual check is the more serious violation, so the
+// violating side loses the game, or the checking side loses if one side
+// checks and the other chases. It's a draw if both sides violate equally,
+// or neither side violates.
+//
+// A move chases a piece if it makes a piece of the mover able to legally
+// capture the piece, which couldn't be captured before the move. The king
+// and the soldiers are free to chase, and neither the king nor a soldier
+// inside its own country can be chased. Attacking a piece of the same
+// value, which can legally capture the attacker back, isn't a chase but an
+// offer to exchange, e.g. a rook attacking a rook. Capturing a protected
+// piece isn't a chase either, unless
+//   - AXF: it's a rook captured by a horse or a cannon;
+//   - Chinese: it's more valuable than the capturing piece.
+
+// opponent returns the opposite color.
+func opponent(color PieceColor) PieceColor {
+	if color == Red {
+		return Black
+	}
+	return Red
+}
+
+// recordHistory records the position right after the move made by `mover`.
+// `chasedBefore` is the result of chasedPieces before the move.
+func (b *Board) recordHistory(mover PieceColor, capture bool, chasedBefore uint64) {
+	b.history = append(b.history, historyEntry{
+		hash:    b.hash,
+		capture: capture,
+		check:   b.isInCheck(opponent(mover)),
+		chase:   b.chasedPieces(mover)&^chasedBefore != 0,
+	})
+}
+
+// judgeRepetition checks whether the current position has occurred for
+// the third time, and returns the result. It returns Ongoing if not.
+func (b *Board) judgeRepetition() GameResult {
+	last := len(b.history) - 1
+	count, first := 1, last
+	for i := last - 1; i >= 0 && !b.history[i+1].capture; i-- {
+		if b.history[i].hash == b.history[last].hash {
+			count++
+			first = i
+		}
+	}
+	if count < repetitionLimit {
+		return Ongoing
+	}
+
+	// The last move is made by the opposite side of the side to move,
+	// and the moves alternate between the two sides.
+	mover := opponent(b.color())
+	moverViolation, otherViolation := violationCheck, violationCheck
+	for i := first + 1; i <= last; i++ {
+		e := b.history[i]
+		if (last-i)%2 == 0 {
+			moverViolation = min(moverViolation, e.violation())
+		} else {
+			otherViolation = min(otherViolation, e.violation())
+		}
+	}
+
+	switch {
+	case moverViolation > otherViolation:
+		return winResult(opponent(mover))
+	case otherViolation > moverViolation:
+		return winResult(mover)
+	}
+	return Draw
+}
+
+// violation ranks how a side violates the rules of the repetitions, by the
+// least serious one of its moves.
+type violation int
+
+const (
+	violationNone violation = iota
+	violationChase
+	violationCheck
+)
+
+// violation returns how the move leading to the entry violates the rules
+// if it's repeated.
+func (e historyEntry) violation() violation {
+	switch {
+	case e.check:
+		return violationCheck
+	case e.chase:
+		return violationChase
+	}
+	return violationNone
+}
+
+// chasedPieces returns the bit set of the slots of the opponent's pieces,
+// which are chased by the pieces of the color in the current position.
+func (b *Board) chasedPieces(color PieceColor) uint64 {
+	var chased uint64
+	tag := sideTag(color)
+	for slot := tag; slot < tag+16; slot++ {
+		from := square(b.pieces[slot])
+		role := slotRole(slot)
+		if from == 0 || role == kingIndex || role == soldierIndex {
+			continue
+		}
+		for _, mv := range b.generatePieceMoves(slot, from, nil) {
+			target := int(b.squares[mv.to()])
+			if target == 0 || chased&(1<<target) != 0 {
+				continue
+			}
+			targetRole := slotRole(target)
+			if targetRole == kingIndex {
+				continue
+			}
+			if targetRole == soldierIndex && ownSide[colorIndex(slotColor(target))][mv.to()] {
+				continue
+			}
+			if !b.isSafeFor(mv, color) {
+				continue
+			}
+			if pieceValues[targetRole] == pieceValues[role] && b.canCaptureBack(mv) {
+				continue
+			}
+			if b.isProtected(mv) && !b.ruleSet.chasesProtected(role, targetRole) {
+				continue
+			}
+			chased |= 1 << target
+		}
+	}
+	return chased
+}
+
+// chasesProtected checks whether capturing a protected piece of the role
+// `target` by the role `attacker` counts as a chase.
+func (r RuleSet) chasesProtected(attacker, target int) bool {
+	if r == RuleSetChinese {
+		return pieceValues[target] > pieceValues[attacker]
+	}
+	return target == rookIndex && (attacker == horseIndex || attacker == cannonIndex)
+}
+
+// isSafeFor checks whether the king of the color is safe after the move,
+// no matter which side is to move.
+func (p *position) isSafeFor(mv move, color PieceColor) bool {
+	p.makeMove(mv)
+	inCheck := p.isInCheck(color)
+	p.unmakeMove()
+	return !inCheck
+}
+
+// canCaptureBack checks whether the piece captured by the move can legally
+// capture the moving piece instead.
+func (p *position) canCaptureBack(mv move) bool {
+	from, to := mv.from(), mv.to()
+	target := int(p.squares[to])
+	for _, reply := range p.generatePieceMoves(target, to, nil) {
+		if reply.to() == from {
+			return p.isSafeFor(reply, slotColor(target))
+		}
+	}
+	return false
+}
+
+// isProtected checks whether the piece captured by the move can be legally
+// recaptured.
+func (p *position) isProtected(mv move) bool {
+	to := mv.to()
+	defender := slotColor(int(p.squares[to]))
+	p.makeMove(mv)
+	defer p.unmakeMove()
+
+	tag := sideTag(defender)
+	for slot := tag; slot < tag+16; slot++ {
+		from := square(p.pieces[slot])
+		if from == 0 {
+			continue
+		}
+		for _, reply := range p.generatePieceMoves(slot, from, nil) {
+			if reply.to() == to && p.isSafeFor(reply, defender) {
+				return true
+			}
+		}
+	}
+	return false
+}
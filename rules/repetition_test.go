@@ -0,0 +1,119 @@
+package rules
+
+import "testing"
+
+func TestRepetition(t *testing.T) {
+	testCases := []struct {
+		name   string
+		fen    string
+		moves  []string
+		winner PieceColor // empty for a draw
+		// the winners of the rule sets which differ from winner
+		winners map[RuleSet]PieceColor
+	}{
+		{
+			name:  "idle repetition",
+			fen:   StartFEN,
+			moves: []string{"b0c2", "b9c7", "c2b0", "c7b9", "b0c2", "b9c7", "c2b0", "c7b9"},
+		},
+		{
+			// The red rook checks the black king on every move.
+			name:   "perpetual check",
+			fen:    "3k5/9/9/9/9/9/9/9/R8/5K3 w - - 0 1",
+			moves:  []string{"a1d1", "d9e9", "d1e1", "e9d9", "e1d1", "d9e9", "d1e1", "e9d9", "e1d1"},
+			winner: Black,
+		},
+		{
+			// The red rook chases the unprotected black cannon on every move.
+			name:   "perpetual chase",
+			fen:    "4k4/9/9/c8/8R/9/9/9/9/3K5 w - - 0 1",
+			moves:  []string{"i5i6", "a6a5", "i6i5", "a5a6", "i5i6", "a6a5", "i6i5", "a5a6"},
+			winner: Black,
+		},
+		{
+			// The red rook attacks the black rook on every move, which can
+			// capture the red rook back, so it's an offer to exchange.
+			name:  "perpetual offer to exchange",
+			fen:   "4k4/9/9/r8/8R/9/9/9/9/3K5 w - - 0 1",
+			moves: []string{"i5i6", "a6a5", "i6i5", "a5a6", "i5i6", "a6a5", "i6i5", "a5a6"},
+		},
+		{
+			// The red rook checks the black king on every move, and each
+			// escape of the king opens a line of a black cannon to chase
+			// the red rook. The perpetual check loses.
+			name:   "perpetual check against perpetual chase",
+			fen:    "4k1b1c/R5n1c/9/9/9/9/9/9/9/3K5 w - - 0 1",
+			moves:  []string{"a8a9", "e9e8", "a9a8", "e8e9", "a8a9", "e9e8", "a9a8", "e8e9"},
+			winner: Black,
+		},
+		{
+			// The red bishop attacks a protected black cannon on every
+			// move, which is a chase under the Chinese rules only, since
+			// the cannon is more valuable than the bishop.
+			name:    "perpetual attack on a protected piece",
+			fen:     "2r1k3r/9/9/9/9/2c6/9/4B3c/9/3K5 w - - 0 1",
+			moves:   []string{"e2g4", "e9f9", "g4e2", "f9e9", "e2g4", "e9f9", "g4e2", "f9e9"},
+			winners: map[RuleSet]PieceColor{RuleSetChinese: Black},
+		},
+	}
+
+	for _, tc := range testCases {
+		for _, ruleSet := range []RuleSet{RuleSetAXF, RuleSetChinese} {
+			t.Run(tc.name+"/"+string(ruleSet), func(t *testing.T) {
+				b, err := ParseFEN(tc.fen)
+				if err != nil {
+					t.Fatalf("Failed to parse the FEN: %v", err)
+				}
+				b.SetRuleSet(ruleSet)
+				for i, s := range tc.moves {
+					if b.IsGameOver() {
+						t.Fatalf("Unexpected game over before move %d", i+1)
+					}
+					m, err := b.ParseICCS(s)
+					if err != nil {
+						t.Fatalf("Failed to parse %s: %v", s, err)
+					}
+					if err := b.ApplyMove(m); err != nil {
+						t.Fatalf("Failed to apply %s: %v", s, err)
+					}
+				}
+
+				if !b.IsGameOver() {
+					t.Fatal("Expected the game to be over")
+				}
+				want, ok := tc.winners[ruleSet]
+				if !ok {
+					want = tc.winner
+				}
+				winner, ok := b.Winner()
+				if ok != (want != "") || winner != want {
+					t.Errorf("Unexpected winner, want: %q, got: %q", want, winner)
+				}
+			})
+		}
+	}
+}
+
+func TestChasesProtected(t *testing.T) {
+	testCases := []struct {
+		ruleSet          RuleSet
+		attacker, target int
+		want             bool
+	}{
+		{RuleSetAXF, horseIndex, rookIndex, true},
+		{RuleSetAXF, cannonIndex, rookIndex, true},
+		{RuleSetAXF, rookIndex, rookIndex, false},
+		{RuleSetAXF, guardIndex, horseIndex, false},
+		{RuleSetChinese, horseIndex, rookIndex, true},
+		{RuleSetChinese, rookIndex, rookIndex, false},
+		{RuleSetChinese, guardIndex, horseIndex, true},
+		{RuleSetChinese, horseIndex, cannonIndex, false},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.ruleSet.chasesProtected(tc.attacker, tc.target); got != tc.want {
+			t.Errorf("Unexpected chasesProtected(%s, %s) under %s, want: %t, got: %t",
+				roleOfIndex[tc.attacker], roleOfIndex[tc.target], tc.ruleSet, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package rules
+
+import "fmt"
+
+// GameResult is the result of a game, in the same form as the PGN.
+type GameResult string
+
+const (
+	Ongoing   = GameResult("*")
+	RedWins   = GameResult("1-0")
+	BlackWins = GameResult("0-1")
+	Draw      = GameResult("1/2-1/2")
+)
+
+// Reason is the reason why the game is over.
+type Reason string
+
+const (
+	// The side to move is in check, and has no legal moves (将死).
+	ReasonCheckmate = Reason("checkmate")
+	// The side to move isn't in check, but has no legal moves (困毙),
+	// which is a loss as well in Chinese chess.
+	ReasonStalemate            = Reason("stalemate")
+	ReasonResignation          = Reason("resignation")
+	ReasonTimeout              = Reason("timeout")
+	ReasonRepetition           = Reason("repetition")
+	ReasonMoveLimit            = Reason("move limit")
+	ReasonInsufficientMaterial = Reason("insufficient material")
+	ReasonAgreement            = Reason("agreement")
+)
+
+// winResult returns the result that the color wins.
+func winResult(color PieceColor) GameResult {
+	if color == Red {
+		return RedWins
+	}
+	return BlackWins
+}
+
+// Winner returns the winner of the result. The second return value is
+// false if the game is ongoing or drawn.
+func (r GameResult) Winner() (PieceColor, bool) {
+	switch r {
+	case RedWins:
+		return Red, true
+	case BlackWins:
+		return Black, true
+	}
+	return "", false
+}
+
+// Describe describes the result with the reason, e.g. "red wins by checkmate".
+func (r GameResult) Describe(reason Reason) string {
+	if winner, ok := r.Winner(); ok {
+		return fmt.Sprintf("%s wins by %s", winner, reason)
+	}
+	if r == Draw {
+		return fmt.Sprintf("draw by %s", reason)
+	}
+	return "ongoing"
+}
+
+// Result returns the result of the game and the reason. The reason is
+// empty if the game is ongoing.
+func (b *Board) Result() (GameResult, Reason) {
+	return b.result, b.reason
+}
+
+// Resign ends the game, and the color loses. It does nothing if the game
+// is already over.
+func (b *Board) Resign(color PieceColor) {
+	b.end(winResult(opponent(color)), ReasonResignation)
+}
+
+// Timeout ends the game because the color runs out of time, and the color
+// loses. The board has no clock, so it's up to the caller to time the game.
+func (b *Board) Timeout(color PieceColor) {
+	b.end(winResult(opponent(color)), ReasonTimeout)
+}
+
+// end ends the game unless it's already over.
+func (b *Board) end(result GameResult, reason Reason) {
+	if b.isGameOver() {
+		return
+	}
+	b.result, b.reason = result, reason
+}
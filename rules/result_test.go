@@ -0,0 +1,100 @@
+package rules
+
+import "testing"
+
+func TestResult(t *testing.T) {
+	testCases := []struct {
+		name   string
+		fen    string
+		end    func(b *Board)
+		result GameResult
+		reason Reason
+	}{
+		{
+			name:   "ongoing",
+			fen:    StartFEN,
+			result: Ongoing,
+		},
+		{
+			name:   "checkmate",
+			fen:    "3k5/9/9/9/9/3R5/9/9/9/4K4 b - - 0 1",
+			result: RedWins,
+			reason: ReasonCheckmate,
+		},
+		{
+			// The black king isn't in check, but it has no legal moves (困毙).
+			name:   "stalemate",
+			fen:    "3k5/8R/9/9/9/9/9/9/9/4K4 b - - 0 1",
+			result: RedWins,
+			reason: ReasonStalemate,
+		},
+		{
+			name:   "resignation",
+			fen:    StartFEN,
+			end:    func(b *Board) { b.Resign(Red) },
+			result: BlackWins,
+			reason: ReasonResignation,
+		},
+		{
+			name:   "timeout",
+			fen:    StartFEN,
+			end:    func(b *Board) { b.Timeout(Black) },
+			result: RedWins,
+			reason: ReasonTimeout,
+		},
+		{
+			name:   "agreement",
+			fen:    StartFEN,
+			end:    func(b *Board) { b.AgreeDraw() },
+			result: Draw,
+			reason: ReasonAgreement,
+		},
+		{
+			// The game is already over, so the resignation is ignored.
+			name:   "resignation after checkmate",
+			fen:    "3k5/9/9/9/9/3R5/9/9/9/4K4 b - - 0 1",
+			end:    func(b *Board) { b.Resign(Red) },
+			result: RedWins,
+			reason: ReasonCheckmate,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := ParseFEN(tc.fen)
+			if err != nil {
+				t.Fatalf("Failed to parse the FEN: %v", err)
+			}
+			if tc.end != nil {
+				tc.end(b)
+			}
+
+			result, reason := b.Result()
+			if result != tc.result || reason != tc.reason {
+				t.Errorf("Unexpected result, want: %s (%s), got: %s (%s)", tc.result, tc.reason, result, reason)
+			}
+			if b.IsGameOver() != (tc.result != Ongoing) {
+				t.Errorf("Unexpected game over: %t", b.IsGameOver())
+			}
+		})
+	}
+}
+
+func TestDescribeResult(t *testing.T) {
+	testCases := []struct {
+		result GameResult
+		reason Reason
+		want   string
+	}{
+		{RedWins, ReasonCheckmate, "red wins by checkmate"},
+		{BlackWins, ReasonRepetition, "black wins by repetition"},
+		{Draw, ReasonMoveLimit, "draw by move limit"},
+		{Ongoing, "", "ongoing"},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.result.Describe(tc.reason); got != tc.want {
+			t.Errorf("Unexpected description, want: %q, got: %q", tc.want, got)
+		}
+	}
+}
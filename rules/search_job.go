@@ -0,0 +1,46 @@
+package rules
+
+import "context"
+
+// SearchJob is a search running in the background. The result is delivered
+// over a channel, so the caller, e.g. the game loop of a GUI, can poll it
+// without sharing any state with the search.
+type SearchJob struct {
+	cancel context.CancelFunc
+	result chan SearchResult
+}
+
+// StartSearch starts to search a snapshot of the board in a new goroutine,
+// so the board can be changed freely while the search is running.
+func (e *Engine) StartSearch(ctx context.Context, b *Board, limits SearchLimits) *SearchJob {
+	ctx, cancel := context.WithCancel(ctx)
+	job := &SearchJob{
+		cancel: cancel,
+		// buffered, so the goroutine never blocks even if nobody receives
+		result: make(chan SearchResult, 1),
+	}
+
+	snapshot := b.Clone()
+	go func() {
+		defer cancel()
+		result := e.Search(ctx, snapshot, limits)
+		// The result of a canceled search is never delivered.
+		if ctx.Err() == nil {
+			job.result <- result
+		}
+	}()
+	return job
+}
+
+// Result returns the channel which delivers the result once the search
+// is done. Nothing is delivered if the search is canceled.
+func (j *SearchJob) Result() <-chan SearchResult {
+	return j.result
+}
+
+// Cancel stops the search. It's safe to call it more than once, or after
+// the search is done. The job should be dropped after it's canceled, since
+// a result delivered right before the cancellation might be in the channel.
+func (j *SearchJob) Cancel() {
+	j.cancel()
+}
@@ -0,0 +1,70 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// The tests drive the search jobs in the same way as the game loop of the
+// GUI, and they are supposed to be run with the race detector as well:
+//
+//	go test -race -run SearchJob ./rules
+
+func TestSearchJob(t *testing.T) {
+	b := NewBoard()
+	job := NewEngine(DefaultEngineOptions).StartSearch(context.Background(), b, SearchLimits{Depth: 3})
+	initial := b.Clone()
+
+	// The board keeps changing while the search is running, which must
+	// not affect the search on the snapshot.
+	timeout := time.After(10 * time.Second)
+	for ply := 0; ; ply++ {
+		select {
+		case result := <-job.Result():
+			if _, ok := initial.FindMove(result.Move.From, result.Move.To); !ok {
+				t.Fatalf("Unexpected illegal move %s", result.Move)
+			}
+			if result.Depth != 3 {
+				t.Errorf("Unexpected depth, want: %d, got: %d", 3, result.Depth)
+			}
+			return
+		case <-timeout:
+			t.Fatal("Timed out waiting for the result")
+		default:
+		}
+
+		if moves := b.LegalMoves(); len(moves) > 0 && ply < 40 {
+			if err := b.ApplyMove(moves[ply%len(moves)]); err != nil {
+				t.Fatalf("Failed to apply the move: %v", err)
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSearchJobCancel(t *testing.T) {
+	b := NewBoard()
+	engine := NewEngine(DefaultEngineOptions)
+
+	// Restart the search on every move, like the GUI does when the
+	// position changes while the AI is thinking.
+	var job *SearchJob
+	for i := 0; i < 10; i++ {
+		if job != nil {
+			job.Cancel()
+		}
+		job = engine.StartSearch(context.Background(), b, SearchLimits{})
+		moves := b.LegalMoves()
+		if err := b.ApplyMove(moves[i%len(moves)]); err != nil {
+			t.Fatalf("Failed to apply the move: %v", err)
+		}
+	}
+
+	job.Cancel()
+	select {
+	case result := <-job.Result():
+		t.Errorf("Unexpected result of the canceled search: %s", result.Move)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
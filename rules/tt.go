@@ -0,0 +1,109 @@
+package rules
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// ttBound tells how the score of a transposition table entry relates to
+// the real score of the position.
+type ttBound uint8
+
+const (
+	// The score is the real score.
+	boundExact ttBound = iota + 1
+	// The real score is at least the score (fail high).
+	boundLower
+	// The real score is at most the score (fail low).
+	boundUpper
+)
+
+// ttEntry is an entry of the transposition table. The score is from the
+// perspective of the side to move, so an entry can be used no matter which
+// side the search is performed for.
+type ttEntry struct {
+	key   uint64
+	score int32
+	move  move
+	depth int8
+	bound ttBound
+}
+
+// ttSlot is the storage of an entry, which is shared by the threads of the
+// search without any lock. The entry is packed into a word (data), and the
+// key is stored XORed with the data, so an entry torn by the concurrent
+// writes of two threads fails to match any key, and is simply missed.
+// Refer to https://www.chessprogramming.org/Shared_Hash_Table#Lockless
+type ttSlot struct {
+	key  atomic.Uint64
+	data atomic.Uint64
+}
+
+// pack packs the entry except the key into a word.
+func (e ttEntry) pack() uint64 {
+	return uint64(uint32(e.score)) | uint64(e.move)<<32 | uint64(uint8(e.depth))<<48 | uint64(e.bound)<<56
+}
+
+// unpackEntry is the reverse of pack.
+func unpackEntry(key, data uint64) ttEntry {
+	return ttEntry{
+		key:   key,
+		score: int32(uint32(data)),
+		move:  move(data >> 32),
+		depth: int8(data >> 48),
+		bound: ttBound(data >> 56),
+	}
+}
+
+// transpositionTable caches the results of the searched positions, which
+// are indexed by the Zobrist hash. The same position is reached by many
+// different move orders in Chinese chess, e.g. the horses and the rooks
+// can be developed in any order, so it saves a lot of searches.
+type transpositionTable struct {
+	entries []ttSlot
+	mask    uint64
+}
+
+// newTranspositionTable creates a transposition table of at most sizeMB
+// megabytes. The number of the entries is a power of two, so the index
+// is simply the low bits of the hash.
+func newTranspositionTable(sizeMB int) *transpositionTable {
+	n := uint64(1)
+	limit := uint64(max(sizeMB, 1)) << 20 / uint64(unsafe.Sizeof(ttSlot{}))
+	for n*2 <= limit {
+		n *= 2
+	}
+	return &transpositionTable{
+		entries: make([]ttSlot, n),
+		mask:    n - 1,
+	}
+}
+
+// probe returns the entry of the position, and false if it isn't found.
+func (tt *transpositionTable) probe(key uint64) (ttEntry, bool) {
+	slot := &tt.entries[key&tt.mask]
+	data := slot.data.Load()
+	// The data of a stored entry is never 0, since the bound isn't.
+	if data == 0 || slot.key.Load()^data != key {
+		return ttEntry{}, false
+	}
+	return unpackEntry(key, data), true
+}
+
+// store saves the result of a search. The entry of another position is
+// always replaced, while the entry of the same position is only replaced
+// by a search of the same or a greater depth.
+func (tt *transpositionTable) store(key uint64, depth int, score int, bound ttBound, mv move) {
+	if e, ok := tt.probe(key); ok && int(e.depth) > depth {
+		return
+	}
+	data := ttEntry{
+		score: int32(score),
+		move:  mv,
+		depth: int8(depth),
+		bound: bound,
+	}.pack()
+	slot := &tt.entries[key&tt.mask]
+	slot.key.Store(key ^ data)
+	slot.data.Store(data)
+}
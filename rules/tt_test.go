@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func TestTranspositionTableSize(t *testing.T) {
+	for _, sizeMB := range []int{1, 3, 16} {
+		tt := newTranspositionTable(sizeMB)
+		n := len(tt.entries)
+		if n&(n-1) != 0 {
+			t.Errorf("Expected a power of two entries for %d MB, got: %d", sizeMB, n)
+		}
+		if bytes := n * int(unsafe.Sizeof(ttSlot{})); bytes > sizeMB<<20 || bytes*2 <= sizeMB<<20 {
+			t.Errorf("Unexpected table size for %d MB, got: %d bytes", sizeMB, bytes)
+		}
+	}
+}
+
+func TestTranspositionTableStore(t *testing.T) {
+	tt := newTranspositionTable(1)
+	key := uint64(0x1234567890abcdef)
+
+	if _, ok := tt.probe(key); ok {
+		t.Fatal("Unexpected entry in the empty table")
+	}
+
+	tt.store(key, 4, 100, boundExact, newMove(toSquare(9, 1), toSquare(7, 2)))
+	e, ok := tt.probe(key)
+	if !ok || e.depth != 4 || e.score != 100 || e.bound != boundExact {
+		t.Fatalf("Unexpected entry: %+v", e)
+	}
+
+	// A shallower search of the same position doesn't replace the entry.
+	tt.store(key, 2, 50, boundLower, 0)
+	if e, _ := tt.probe(key); e.depth != 4 || e.score != 100 {
+		t.Errorf("Unexpected replacement by a shallower search: %+v", e)
+	}
+
+	// Another position with the same index always replaces the entry.
+	other := key + uint64(len(tt.entries))
+	tt.store(other, 1, -20, boundUpper, 0)
+	if _, ok := tt.probe(key); ok {
+		t.Error("Expected the entry to be replaced")
+	}
+	if e, ok := tt.probe(other); !ok || e.score != -20 || e.bound != boundUpper {
+		t.Errorf("Unexpected entry: %+v", e)
+	}
+}
+
+func TestEngineReuse(t *testing.T) {
+	// The second search of the same position reuses the entries stored by
+	// the first one, which are from the perspective of the side to move.
+	engine := NewEngine(DefaultEngineOptions)
+	b, err := ParseFEN(perftCases[1].fen)
+	if err != nil {
+		t.Fatalf("Failed to parse the FEN: %v", err)
+	}
+	first := engine.Search(context.Background(), b, SearchLimits{Depth: 4})
+	second := engine.Search(context.Background(), b, SearchLimits{Depth: 4})
+	if second.Score != first.Score {
+		t.Errorf("Unexpected score of the second search, want: %d, got: %d", first.Score, second.Score)
+	}
+	if second.Nodes >= first.Nodes {
+		t.Errorf("Expected fewer nodes in the second search, first: %d, second: %d", first.Nodes, second.Nodes)
+	}
+}
+
+func TestTranspositionTableConcurrent(t *testing.T) {
+	// The threads keep storing the entries of the keys colliding at the same
+	// index, and any entry found must be the one stored for the key, never
+	// torn by the concurrent writes.
+	tt := newTranspositionTable(1)
+	stride := uint64(len(tt.entries))
+	scoreOf := func(key uint64) int { return int(key / stride) }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10000; j++ {
+				key := 7 + uint64((i*10000+j)%16)*stride
+				tt.store(key, 1, scoreOf(key), boundExact, 0)
+				probed := 7 + uint64(j%16)*stride
+				if e, ok := tt.probe(probed); ok && int(e.score) != scoreOf(probed) {
+					t.Errorf("Unexpected torn entry of the key %x: %+v", probed, e)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
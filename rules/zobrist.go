@@ -0,0 +1,58 @@
+package rules
+
+// The Zobrist keys of all the pieces on all the squares, and the key of
+// the side to move. The keys are generated from a fixed seed, so the hash
+// of a position is the same in every run, which is required by an opening
+// book for example.
+var (
+	// [0~6] for the Red pieces, and [7~13] for the Black pieces
+	zobristPieceKeys [14][256]uint64
+	zobristBlackKey  uint64
+)
+
+func init() {
+	// splitmix64, refer to https://prng.di.unimi.it/splitmix64.c
+	seed := uint64(0x9e3779b97f4a7c15)
+	next := func() uint64 {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+
+	for k := range zobristPieceKeys {
+		for i := 0; i <= 9; i++ {
+			for j := 0; j <= 8; j++ {
+				zobristPieceKeys[k][toSquare(i, j)] = next()
+			}
+		}
+	}
+	zobristBlackKey = next()
+}
+
+// zobristKey returns the key of the piece of the slot on the square.
+func zobristKey(slot int, sq square) uint64 {
+	return zobristPieceKeys[pieceIndex(slot)][sq]
+}
+
+// computeHash computes the Zobrist hash of the position from scratch.
+func (p *position) computeHash() uint64 {
+	var h uint64
+	for slot := redTag; slot < blackTag+16; slot++ {
+		if sq := p.pieces[slot]; sq != 0 {
+			h ^= zobristKey(slot, square(sq))
+		}
+	}
+	if !p.isRedTurn {
+		h ^= zobristBlackKey
+	}
+	return h
+}
+
+// Hash returns the 64-bit Zobrist hash of the position, which covers the
+// pieces on the board and the side to move. It's kept up to date on every
+// move, so it's cheap to get.
+func (b *Board) Hash() uint64 {
+	return b.hash
+}
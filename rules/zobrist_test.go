@@ -0,0 +1,65 @@
+package rules
+
+import "testing"
+
+func TestHashIncremental(t *testing.T) {
+	b := NewBoard()
+	initial := b.Hash()
+
+	// Play some legal moves, and check the incremental
+	// hash against the one computed from scratch after each move.
+	for i := 0; i < 40; i++ {
+		moves := b.legalMoves(nil)
+		if len(moves) == 0 {
+			break
+		}
+		mv := moves[(i*7)%len(moves)]
+		m := b.toMove(mv)
+		b.makeMove(mv)
+		if got, want := b.Hash(), b.computeHash(); got != want {
+			t.Fatalf("Unexpected hash after %s, want: %x, got: %x", m.ICCS(), want, got)
+		}
+	}
+
+	for len(b.undoStack) > 0 {
+		b.unmakeMove()
+	}
+	if b.Hash() != initial {
+		t.Errorf("Unexpected hash after taking back all the moves, want: %x, got: %x", initial, b.Hash())
+	}
+}
+
+func TestHashTransposition(t *testing.T) {
+	play := func(moves ...string) *Board {
+		b := NewBoard()
+		for _, s := range moves {
+			m, err := b.ParseICCS(s)
+			if err != nil {
+				t.Fatalf("Failed to parse %s: %v", s, err)
+			}
+			if err := b.ApplyMove(m); err != nil {
+				t.Fatalf("Failed to apply %s: %v", s, err)
+			}
+		}
+		return b
+	}
+
+	b1 := play("h2e2", "h9g7", "b0c2", "b9c7")
+	b2 := play("b0c2", "b9c7", "h2e2", "h9g7")
+	if b1.Hash() != b2.Hash() {
+		t.Errorf("Expected the same hash for the transposed positions, got: %x and %x", b1.Hash(), b2.Hash())
+	}
+
+	b3 := play("h2e2", "h9g7", "b0c2")
+	if b1.Hash() == b3.Hash() {
+		t.Errorf("Expected different hashes for different positions, got: %x", b1.Hash())
+	}
+
+	fromFEN, err := ParseFEN(b1.FEN())
+	if err != nil {
+		t.Fatalf("Failed to parse the FEN: %v", err)
+	}
+	if fromFEN.Hash() != b1.Hash() {
+		t.Errorf("Unexpected hash of the parsed FEN, want: %x, got: %x", b1.Hash(), fromFEN.Hash())
+	}
+}
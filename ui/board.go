@@ -0,0 +1,259 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/ahrtr/chess/rules"
+	"github.com/ahrtr/chess/utils"
+)
+
+// Board renders a rules.Board and translates the mouse input into moves.
+// All the game logic is delegated to the position.
+type Board struct {
+	// The position of the game
+	position *rules.Board
+	// What's your color: red or black?
+	selfColor rules.PieceColor
+	// Is the MouseButtonLeft pressed?
+	mouseDown bool
+	// The point clicked by mouse
+	// Note the point.X is the row number (0-9), and point.Y is the column number (0-8)
+	// of the position, which might be different from the point on the screen.
+	targetPt *image.Point
+	// The piece on the selected point should be displayed in dash circle.
+	// Note the point.X is the row number (0-9), and point.Y is the column number (0-8)
+	// of the position, which might be different from the point on the screen.
+	selectedFromPoint *image.Point
+	// What's the start time that the player is allowed to move?
+	startTime time.Time
+	// the time that generates the winner.
+	finalTime time.Time
+	// the notation to display the moves
+	notation Notation
+	// the moves which have been played
+	moveList []moveRecord
+	// the hint from the AI
+	hintFromAI string
+	// the move suggested by the AI, which is highlighted on the board
+	hintMove *rules.Move
+	// whether the AI is working
+	isAIWorking bool
+	// the time when the AI starts to work
+	aiStartTime time.Time
+	aiStopTime  time.Time
+}
+
+// NewBoard creates a board to play the position. It starts from the
+// initial position if the position is nil.
+func NewBoard(selfColor rules.PieceColor, position *rules.Board) (*Board, error) {
+	if err := initializePieceImageMap(); err != nil {
+		return nil, err
+	}
+
+	if position == nil {
+		position = rules.NewBoard()
+	}
+	return &Board{
+		position:  position,
+		selfColor: selfColor,
+		notation:  NotationWXF,
+		startTime: time.Now(),
+	}, nil
+}
+
+// SetNotation sets the notation used by the hint and the move list.
+func (b *Board) SetNotation(n Notation) {
+	b.notation = n
+}
+
+func (b *Board) Clone() *Board {
+	clone := &Board{
+		position:  b.position.Clone(),
+		selfColor: b.selfColor,
+		notation:  b.notation,
+		moveList:  slices.Clone(b.moveList),
+		mouseDown: b.mouseDown,
+		startTime: b.startTime,
+		finalTime: b.finalTime,
+	}
+	if b.selectedFromPoint != nil {
+		clone.selectedFromPoint = &image.Point{X: b.selectedFromPoint.X, Y: b.selectedFromPoint.Y}
+	}
+	if b.targetPt != nil {
+		clone.targetPt = &image.Point{X: b.targetPt.X, Y: b.targetPt.Y}
+	}
+	return clone
+}
+
+// Position returns the position of the game.
+func (b *Board) Position() *rules.Board {
+	return b.position
+}
+
+func (b *Board) ResetTimer() {
+	b.startTime = time.Now()
+}
+
+// Update returns true if any piece moves, returns false otherwise.
+func (b *Board) Update() bool {
+	if b.position.IsGameOver() {
+		return false
+	}
+
+	moved := false
+
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		pt := image.Pt(ebiten.CursorPosition())
+
+		targetPt := b.findMouseClickedPoint(pt)
+		if targetPt != nil {
+			b.mouseDown = true
+			b.targetPt = targetPt
+		} else {
+			b.mouseDown = false
+			b.targetPt = nil
+		}
+	} else {
+		if b.mouseDown {
+			p := b.position.PieceAt(*b.targetPt)
+			if p == nil {
+				if b.selectedFromPoint != nil {
+					// check whether it's a valid move.
+					if m, ok := b.position.FindMove(*b.selectedFromPoint, *b.targetPt); ok {
+						b.move(m)
+						moved = true
+					}
+					b.selectedFromPoint = nil
+				}
+			} else {
+				if b.selectedFromPoint == nil {
+					// You can't select a piece of the wrong color unless you are capturing the opponent's pieces.
+					if (p.Color() == rules.Red) == b.position.IsRedTurn() {
+						b.selectedFromPoint = b.targetPt
+					}
+				} else {
+					// You can't capture a piece of the same color.
+					if (p.Color() == rules.Red) != b.position.IsRedTurn() {
+						// check whether it's a valid capture.
+						if m, ok := b.position.FindMove(*b.selectedFromPoint, *b.targetPt); ok {
+							b.move(m)
+							moved = true
+						}
+					}
+					b.selectedFromPoint = nil
+				}
+			}
+		}
+		b.mouseDown = false
+	}
+
+	return moved
+}
+
+// AgreeDraw ends the game in a draw agreed by both players.
+func (b *Board) AgreeDraw() {
+	if b.position.IsGameOver() {
+		return
+	}
+	b.position.AgreeDraw()
+	b.resetAI()
+	b.finalTime = time.Now()
+}
+
+// Resign ends the game, and the side to move loses.
+func (b *Board) Resign() {
+	if b.position.IsGameOver() {
+		return
+	}
+	color := rules.Black
+	if b.position.IsRedTurn() {
+		color = rules.Red
+	}
+	b.position.Resign(color)
+	b.resetAI()
+	b.finalTime = time.Now()
+}
+
+func (b *Board) move(m rules.Move) {
+	record := moveRecord{
+		number: b.position.FullMoveNumber(),
+		isRed:  b.position.IsRedTurn(),
+		text:   formatMove(b.position, m, b.notation),
+	}
+	if err := b.position.ApplyMove(m); err != nil {
+		panic(err)
+	}
+	b.moveList = append(b.moveList, record)
+	b.resetAI()
+	if b.position.IsGameOver() {
+		b.finalTime = time.Now()
+		return
+	}
+	b.startTime = time.Now()
+}
+
+// findMouseClickedPoint locates the point clicked by the mouse.
+// Note the input parameter is the position of the cursor when
+// mouse being clicked; while the return parameter is the position
+// [row number(0-9): column number(0-8)] on the board.
+func (b *Board) findMouseClickedPoint(pt image.Point) *image.Point {
+	var (
+		// step of rows and columns
+		widthStep, heightStep = (boardAreaWidth - leftMargin*2) / 8, (WindowsHeight - topMargin*2) / 9
+	)
+
+	for i := 0; i < 10; i++ { // 10 rows
+		for j := 0; j < 9; j++ { // 9 columns
+			targetPt := image.Pt(leftMargin+widthStep*j, topMargin+heightStep*i)
+			rect := image.Rect(targetPt.X-imageWidth/2, targetPt.Y-imageHeight/2, targetPt.X+imageWidth/2, targetPt.Y+imageHeight/2)
+			if !utils.IsPointInsideRect(pt, rect) {
+				continue
+			}
+			boardPt := b.flip(image.Point{X: i, Y: j})
+			return &boardPt
+		}
+	}
+
+	return nil
+}
+
+// flip converts a point of the position to the point on the screen, or
+// vice versa. The position always has the Black pieces on the top, so the
+// board is rotated by 180 degrees when you play black.
+func (b *Board) flip(pt image.Point) image.Point {
+	if b.selfColor == rules.Red {
+		return pt
+	}
+	return image.Point{X: 9 - pt.X, Y: 8 - pt.Y}
+}
+
+func (b *Board) StartAI() {
+	b.isAIWorking = true
+	b.aiStartTime = time.Now()
+}
+
+// StopAI shows the result of the AI search, which must be made in the
+// current position.
+func (b *Board) StopAI(result rules.SearchResult) {
+	b.isAIWorking = false
+	score := fmt.Sprintf("score: %d", result.Score)
+	if n := result.MateIn(); n != 0 {
+		score = fmt.Sprintf("mate in %d", n)
+	}
+	b.hintFromAI = fmt.Sprintf("Best move: %s (%s, depth: %d)", formatMove(b.position, result.Move, b.notation), score, result.Depth)
+	b.hintMove = &result.Move
+	b.aiStopTime = time.Now()
+}
+
+func (b *Board) resetAI() {
+	b.isAIWorking = false
+	b.hintFromAI = ""
+	b.hintMove = nil
+	b.aiStartTime = time.Now()
+	b.aiStopTime = time.Now()
+}
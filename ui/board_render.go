@@ -1,9 +1,10 @@
-package rules
+package ui
 
 import (
 	"fmt"
 	"image"
 	"image/color"
+	"strings"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -11,12 +12,17 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/vector"
 
 	"github.com/ahrtr/chess/fonts"
+	"github.com/ahrtr/chess/rules"
 )
 
 const (
-	WindowsWidth  = 640
+	WindowsWidth  = boardAreaWidth + moveListWidth
 	WindowsHeight = 840
 
+	// The board is on the left side, and the move list is on the right side.
+	boardAreaWidth = 640
+	moveListWidth  = 200
+
 	leftMargin = 40
 	topMargin  = 80
 
@@ -25,16 +31,28 @@ const (
 
 	msgFontSize     = 16
 	msgBottomMargin = 35
+
+	moveListFontSize   = 14
+	moveListLineHeight = 20
+
+	bannerFontSize = 28
+	bannerHeight   = 80
 )
 
 var (
-	boardBackgroundColor = color.RGBA{R: 0xbb, G: 0xad, B: 0xa0, A: 0xff}
+	boardBackgroundColor    = color.RGBA{R: 0xbb, G: 0xad, B: 0xa0, A: 0xff}
+	moveListBackgroundColor = color.RGBA{R: 0xa8, G: 0x9a, B: 0x8d, A: 0xff}
+	hintMoveColor           = color.RGBA{R: 0x00, G: 0x80, B: 0x00, A: 0xff}
+	bannerBackgroundColor   = color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0xb0}
 )
 
 func (b *Board) Draw(screen *ebiten.Image) {
 	drawBoard(screen)
 	b.drawPieces(screen)
+	b.drawHintMove(screen)
 	b.drawMessage(screen)
+	b.drawMoveList(screen)
+	b.drawGameOverBanner(screen)
 }
 
 func drawBoard(screen *ebiten.Image) {
@@ -43,8 +61,8 @@ func drawBoard(screen *ebiten.Image) {
 	bounds := screen.Bounds()
 
 	var (
-		// width & height of the windows
-		windowsWidth, windowsHeight = bounds.Max.X - bounds.Min.X, bounds.Max.Y - bounds.Min.Y
+		// width & height of the windows (excluding the move list)
+		windowsWidth, windowsHeight = boardAreaWidth, bounds.Max.Y - bounds.Min.Y
 		// width & height of the game area
 		boardWidth, boardHeight = windowsWidth - leftMargin*2, windowsHeight - topMargin*2
 
@@ -93,35 +111,56 @@ func drawBoard(screen *ebiten.Image) {
 func (b *Board) drawPieces(screen *ebiten.Image) {
 	bounds := screen.Bounds()
 	var (
-		// width & height of the windows
-		windowsWidth, windowsHeight = bounds.Max.X - bounds.Min.X, bounds.Max.Y - bounds.Min.Y
+		// width & height of the windows (excluding the move list)
+		windowsWidth, windowsHeight = boardAreaWidth, bounds.Max.Y - bounds.Min.Y
 		// step of rows and columns
 		widthStep, heightStep = (windowsWidth - leftMargin*2) / 8, (windowsHeight - topMargin*2) / 9
 	)
 
 	for i := 0; i < 10; i++ { // 10 rows
 		for j := 0; j < 9; j++ { // 9 columns
-			p := b.pieceMatrix[i][j]
+			p := b.position.PieceAt(image.Point{X: i, Y: j})
 			if p == nil {
 				continue
 			}
 
 			var img *ebiten.Image
-			if (b.selectedFromPoint != nil) && (*b.selectedFromPoint == image.Point{X: i, Y: j}) {
+			if (b.selectedFromPoint != nil) && (*b.selectedFromPoint == image.Point{X: i, Y: j}) ||
+				(b.hintMove != nil) && (b.hintMove.From == image.Point{X: i, Y: j}) {
 				img = pieceImageMap[*p][1]
 			} else {
 				img = pieceImageMap[*p][0]
 			}
 
+			screenPt := b.flip(image.Point{X: i, Y: j})
 			op := &ebiten.DrawImageOptions{}
 			op.GeoM.Translate(-float64(imageWidth/2), -float64(imageHeight/2))
-			op.GeoM.Translate(float64(leftMargin+widthStep*j), float64(topMargin+heightStep*i))
+			op.GeoM.Translate(float64(leftMargin+widthStep*screenPt.Y), float64(topMargin+heightStep*screenPt.X))
 
 			screen.DrawImage(img, op)
 		}
 	}
 }
 
+// drawHintMove marks the target point of the move suggested by the AI.
+func (b *Board) drawHintMove(screen *ebiten.Image) {
+	if b.hintMove == nil {
+		return
+	}
+
+	bounds := screen.Bounds()
+	var (
+		// width & height of the windows (excluding the move list)
+		windowsWidth, windowsHeight = boardAreaWidth, bounds.Max.Y - bounds.Min.Y
+		// step of rows and columns
+		widthStep, heightStep = (windowsWidth - leftMargin*2) / 8, (windowsHeight - topMargin*2) / 9
+	)
+
+	screenPt := b.flip(b.hintMove.To)
+	x, y := float32(leftMargin+widthStep*screenPt.Y), float32(topMargin+heightStep*screenPt.X)
+	vector.StrokeCircle(screen, x, y, float32(imageWidth/2), borderLineWidth, hintMoveColor, true)
+}
+
 func (b *Board) drawMessage(screen *ebiten.Image) {
 	b.drawTimerAndWinner(screen)
 	b.drawHintFromAI(screen)
@@ -129,19 +168,29 @@ func (b *Board) drawMessage(screen *ebiten.Image) {
 
 func (b *Board) drawTimerAndWinner(screen *ebiten.Image) {
 	timeElapsed := time.Since(b.startTime)
-	if b.finalTime.After(b.startTime) {
-		timeElapsed = b.finalTime.Sub(b.startTime)
+	if b.position.IsGameOver() {
+		timeElapsed = max(b.finalTime.Sub(b.startTime), 0)
 	}
 	msg := timeElapsed.Round(time.Second).String()
-	if b.finalTime.After(b.startTime) {
-		msg += "  winner!"
-	}
 
 	op := &text.DrawOptions{}
 
 	bounds := screen.Bounds()
 	windowsHeight := bounds.Max.Y - bounds.Min.Y
-	if (b.selfColor == Red && b.isRedTurn) || (b.selfColor == Black && !b.isRedTurn) {
+	// The timer of the winner is displayed once the game is over, and
+	// the timer of yourself is displayed for a draw. The details of the
+	// result are displayed by the banner.
+	isRedSide := b.position.IsRedTurn()
+	if b.position.IsGameOver() {
+		if winner, ok := b.position.Winner(); ok {
+			msg += "  winner!"
+			isRedSide = winner == rules.Red
+		} else {
+			msg += "  draw"
+			isRedSide = b.selfColor == rules.Red
+		}
+	}
+	if (b.selfColor == rules.Red) == isRedSide {
 		// print the timer at the bottom
 		op.GeoM.Translate(10, float64(windowsHeight-msgBottomMargin))
 
@@ -180,3 +229,64 @@ func (b *Board) drawHintFromAI(screen *ebiten.Image) {
 		Size:   msgFontSize,
 	}, op)
 }
+
+// drawMoveList draws the latest moves which fit in the move list area.
+func (b *Board) drawMoveList(screen *ebiten.Image) {
+	bounds := screen.Bounds()
+	windowsHeight := bounds.Max.Y - bounds.Min.Y
+	vector.DrawFilledRect(screen, float32(boardAreaWidth), 0, float32(moveListWidth), float32(windowsHeight), moveListBackgroundColor, false)
+
+	var lines []string
+	for i, r := range b.moveList {
+		switch {
+		case r.isRed:
+			lines = append(lines, fmt.Sprintf("%d. %s", r.number, r.text))
+		case i == 0:
+			lines = append(lines, fmt.Sprintf("%d. ...  %s", r.number, r.text))
+		default:
+			lines[len(lines)-1] += "  " + r.text
+		}
+	}
+
+	maxLines := (windowsHeight - topMargin*2) / moveListLineHeight
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+
+	face := &text.GoTextFace{
+		Source: fonts.TextFaceSource,
+		Size:   moveListFontSize,
+	}
+	for i, line := range lines {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(boardAreaWidth+10), float64(topMargin+moveListLineHeight*i))
+		text.Draw(screen, line, face, op)
+	}
+}
+
+// drawGameOverBanner draws the result and the reason across the middle
+// of the board once the game is over, e.g. "Red wins by checkmate".
+func (b *Board) drawGameOverBanner(screen *ebiten.Image) {
+	result, reason := b.position.Result()
+	if result == rules.Ongoing {
+		return
+	}
+
+	bounds := screen.Bounds()
+	windowsHeight := bounds.Max.Y - bounds.Min.Y
+	top := (windowsHeight - bannerHeight) / 2
+	vector.DrawFilledRect(screen, 0, float32(top), float32(boardAreaWidth), float32(bannerHeight), bannerBackgroundColor, false)
+
+	msg := result.Describe(reason)
+	msg = strings.ToUpper(msg[:1]) + msg[1:]
+
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(boardAreaWidth/2), float64(windowsHeight/2))
+	op.PrimaryAlign = text.AlignCenter
+	op.SecondaryAlign = text.AlignCenter
+	op.ColorScale.ScaleWithColor(color.White)
+	text.Draw(screen, msg, &text.GoTextFace{
+		Source: fonts.TextFaceSource,
+		Size:   bannerFontSize,
+	}, op)
+}
@@ -0,0 +1,34 @@
+package ui
+
+import "github.com/ahrtr/chess/rules"
+
+// Notation is the notation to display the moves.
+type Notation string
+
+const (
+	NotationWXF     = Notation("wxf")
+	NotationICCS    = Notation("iccs")
+	NotationChinese = Notation("chinese")
+)
+
+// moveRecord is a move in the move list.
+type moveRecord struct {
+	// the full move number of the move
+	number int
+	// whether it's a Red's move
+	isRed bool
+	// the move in the notation of the board
+	text string
+}
+
+// formatMove formats the move, which must be a legal move in the position.
+func formatMove(position *rules.Board, m rules.Move, n Notation) string {
+	switch n {
+	case NotationICCS:
+		return m.ICCS()
+	case NotationChinese:
+		return position.Chinese(m)
+	default:
+		return position.WXF(m)
+	}
+}
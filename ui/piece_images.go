@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/png"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/ahrtr/chess/images"
+	"github.com/ahrtr/chess/rules"
+)
+
+var (
+	pieceSolidImageDataMap = map[rules.Piece][]byte{
+		rules.NewPiece(rules.Red, rules.RoleRook):   images.RedRookPng,
+		rules.NewPiece(rules.Red, rules.RoleHorse):  images.RedHorsePng,
+		rules.NewPiece(rules.Red, rules.RoleBishop): images.RedBishopPng,
+		rules.NewPiece(rules.Red, rules.RoleGuard):  images.RedGuardPng,
+		rules.NewPiece(rules.Red, rules.RoleKing):   images.RedKingPng,
+		rules.NewPiece(rules.Red, rules.RoleCannon): images.RedCannonPng,
+		rules.NewPiece(rules.Red, rules.RoleSolder): images.RedSoldierPng,
+
+		rules.NewPiece(rules.Black, rules.RoleRook):   images.BlackRookPng,
+		rules.NewPiece(rules.Black, rules.RoleHorse):  images.BlackHorsePng,
+		rules.NewPiece(rules.Black, rules.RoleBishop): images.BlackBishopPng,
+		rules.NewPiece(rules.Black, rules.RoleGuard):  images.BlackGuardPng,
+		rules.NewPiece(rules.Black, rules.RoleKing):   images.BlackKingPng,
+		rules.NewPiece(rules.Black, rules.RoleCannon): images.BlackCannonPng,
+		rules.NewPiece(rules.Black, rules.RoleSolder): images.BlackSoldierPng,
+	}
+
+	pieceDashImageDataMap = map[rules.Piece][]byte{
+		rules.NewPiece(rules.Red, rules.RoleRook):   images.RedRookDashPng,
+		rules.NewPiece(rules.Red, rules.RoleHorse):  images.RedHorseDashPng,
+		rules.NewPiece(rules.Red, rules.RoleBishop): images.RedBishopDashPng,
+		rules.NewPiece(rules.Red, rules.RoleGuard):  images.RedGuardDashPng,
+		rules.NewPiece(rules.Red, rules.RoleKing):   images.RedKingDashPng,
+		rules.NewPiece(rules.Red, rules.RoleCannon): images.RedCannonDashPng,
+		rules.NewPiece(rules.Red, rules.RoleSolder): images.RedSoldierDashPng,
+
+		rules.NewPiece(rules.Black, rules.RoleRook):   images.BlackRookDashPng,
+		rules.NewPiece(rules.Black, rules.RoleHorse):  images.BlackHorseDashPng,
+		rules.NewPiece(rules.Black, rules.RoleBishop): images.BlackBishopDashPng,
+		rules.NewPiece(rules.Black, rules.RoleGuard):  images.BlackGuardDashPng,
+		rules.NewPiece(rules.Black, rules.RoleKing):   images.BlackKingDashPng,
+		rules.NewPiece(rules.Black, rules.RoleCannon): images.BlackCannonDashPng,
+		rules.NewPiece(rules.Black, rules.RoleSolder): images.BlackSoldierDashPng,
+	}
+
+	pieceImageMap = map[rules.Piece][2]*ebiten.Image{
+		rules.NewPiece(rules.Red, rules.RoleRook):   {nil, nil},
+		rules.NewPiece(rules.Red, rules.RoleHorse):  {nil, nil},
+		rules.NewPiece(rules.Red, rules.RoleBishop): {nil, nil},
+		rules.NewPiece(rules.Red, rules.RoleGuard):  {nil, nil},
+		rules.NewPiece(rules.Red, rules.RoleKing):   {nil, nil},
+		rules.NewPiece(rules.Red, rules.RoleCannon): {nil, nil},
+		rules.NewPiece(rules.Red, rules.RoleSolder): {nil, nil},
+
+		rules.NewPiece(rules.Black, rules.RoleRook):   {nil, nil},
+		rules.NewPiece(rules.Black, rules.RoleHorse):  {nil, nil},
+		rules.NewPiece(rules.Black, rules.RoleBishop): {nil, nil},
+		rules.NewPiece(rules.Black, rules.RoleGuard):  {nil, nil},
+		rules.NewPiece(rules.Black, rules.RoleKing):   {nil, nil},
+		rules.NewPiece(rules.Black, rules.RoleCannon): {nil, nil},
+		rules.NewPiece(rules.Black, rules.RoleSolder): {nil, nil},
+	}
+
+	// All images are supposed to have the same size.
+	imageWidth  int
+	imageHeight int
+)
+
+func initializePieceImageMap() error {
+	for p, _ := range pieceImageMap {
+		solidImg, _, err := image.Decode(bytes.NewReader(pieceImageData(p, false)))
+		if err != nil {
+			return fmt.Errorf("error loading solid image for piece (%v): %w", p, err)
+		}
+		dashImg, _, err := image.Decode(bytes.NewReader(pieceImageData(p, true)))
+		if err != nil {
+			return fmt.Errorf("error loading dash image for piece (%v): %w", p, err)
+		}
+		pieceImageMap[p] = [2]*ebiten.Image{ebiten.NewImageFromImage(solidImg), ebiten.NewImageFromImage(dashImg)}
+	}
+
+	img := pieceImageMap[rules.NewPiece(rules.Red, rules.RoleRook)][0]
+	imgBound := img.Bounds()
+	imageWidth, imageHeight = imgBound.Max.X-imgBound.Min.X, imgBound.Max.Y-imgBound.Min.Y
+
+	return nil
+}
+
+func pieceImageData(p rules.Piece, isDash bool) []byte {
+	if isDash {
+		return pieceDashImageDataMap[p]
+	}
+	return pieceSolidImageDataMap[p]
+}